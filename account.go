@@ -0,0 +1,79 @@
+package aip11
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// This file standardizes the customizationContext used to derive multiple
+// accounts from one Entropy-Seed.
+//
+// AIP11 notes that the customizationContext of EntropySeedToMasterSeed
+// "allows to support the case of generating multiple accounts from one
+// mnemonic", but does not fix an encoding. The encoding used here is
+//
+//	AccountIndexContext(0) = ""                                 (AIP11 default)
+//	AccountIndexContext(n) = "AccountIndex" || uint32_be(n)     (n > 0)
+//
+// so account 0 is exactly the account every AIP11 wallet already derives.
+
+// Errors
+
+var (
+	ErrAccountIndexExhausted = errors.New("account index space is exhausted")
+)
+
+const accountIndexLabel = "AccountIndex"
+
+// MaxAccountIndex is the largest account index that can be encoded.
+const MaxAccountIndex = ^uint32(0)
+
+// AccountIndexContext returns the standardized customizationContext for the
+// account with the given index.
+func AccountIndexContext(n uint32) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+	context := make([]byte, len(accountIndexLabel)+4)
+	copy(context, accountIndexLabel)
+	binary.BigEndian.PutUint32(context[len(accountIndexLabel):], n)
+	return context
+}
+
+// AccountN derives the master seed of the n-th account from the entropy seed.
+// AccountN(entropySeed, 0) equals EntropySeedToMasterSeed(entropySeed, []byte{}).
+func AccountN(entropySeed []byte, n uint32) ([]byte, error) {
+	return EntropySeedToMasterSeed(entropySeed, AccountIndexContext(n))
+}
+
+// AccountUsedFunc reports whether the account with the given index and master
+// seed has been used.
+type AccountUsedFunc func(n uint32, masterSeed []byte) (bool, error)
+
+// DiscoverAccounts walks account indices from 0 upwards and returns the number
+// of consecutive accounts for which isUsed reports true. The accounts in use
+// are therefore 0 to count-1.
+func DiscoverAccounts(entropySeed []byte, isUsed AccountUsedFunc) (uint32, error) {
+	if len(entropySeed) != 32 {
+		return 0, ErrEntropySeedInvalid
+	}
+
+	n := uint32(0)
+	for {
+		masterSeed, err := AccountN(entropySeed, n)
+		if err != nil {
+			return 0, err
+		}
+		used, err := isUsed(n, masterSeed)
+		if err != nil {
+			return 0, err
+		}
+		if !used {
+			return n, nil
+		}
+		if n == MaxAccountIndex {
+			return 0, ErrAccountIndexExhausted
+		}
+		n++
+	}
+}
@@ -0,0 +1,88 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleAccountN() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.AccountN(entropySeed, 3)
+	fmt.Println(len(masterSeed))
+	// Output: 64
+}
+
+func TestAccountIndexContext(t *testing.T) {
+	testCases := []struct {
+		n        uint32
+		expected string
+	}{
+		{n: 0, expected: ""},
+		{n: 1, expected: hex.EncodeToString([]byte("AccountIndex")) + "00000001"},
+		{n: 0x01020304, expected: hex.EncodeToString([]byte("AccountIndex")) + "01020304"},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, hex.EncodeToString(aip11.AccountIndexContext(tc.n)), "Account index context should be encoded correctly")
+		})
+	}
+}
+
+func TestAccountN(t *testing.T) {
+	vectors := getAIP11Vector()
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			entropySeed, err := hex.DecodeString(v.entropySeed)
+			assert.NoError(t, err, "Entropy seed should be decoded correctly")
+
+			account0, err := aip11.AccountN(entropySeed, 0)
+			assert.NoError(t, err, "Account 0 should be derived correctly")
+			assert.Equal(t, v.masterSeed, hex.EncodeToString(account0), "Account 0 should be the AIP11 default account")
+
+			account1, err := aip11.AccountN(entropySeed, 1)
+			assert.NoError(t, err, "Account 1 should be derived correctly")
+			expected, err := aip11.EntropySeedToMasterSeed(entropySeed, aip11.AccountIndexContext(1))
+			assert.NoError(t, err, "Master seed should be generated correctly")
+			assert.Equal(t, expected, account1, "Account 1 should use the account index context")
+			assert.NotEqual(t, account0, account1, "Accounts should be different")
+		})
+	}
+
+	_, err := aip11.AccountN([]byte{1, 2, 3}, 1)
+	assert.ErrorIs(t, err, aip11.ErrEntropySeedInvalid, "Invalid entropy seed should be rejected")
+}
+
+func TestDiscoverAccounts(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+
+	used := map[string]bool{}
+	for n := uint32(0); n < 3; n++ {
+		masterSeed, err := aip11.AccountN(entropySeed, n)
+		assert.NoError(t, err, "Account should be derived correctly")
+		used[hex.EncodeToString(masterSeed)] = true
+	}
+
+	count, err := aip11.DiscoverAccounts(entropySeed, func(n uint32, masterSeed []byte) (bool, error) {
+		return used[hex.EncodeToString(masterSeed)], nil
+	})
+	assert.NoError(t, err, "Accounts should be discovered correctly")
+	assert.Equal(t, uint32(3), count, "Three accounts should be discovered")
+
+	count, err = aip11.DiscoverAccounts(entropySeed, func(n uint32, masterSeed []byte) (bool, error) {
+		return false, nil
+	})
+	assert.NoError(t, err, "Accounts should be discovered correctly")
+	assert.Equal(t, uint32(0), count, "No account should be discovered")
+
+	errOracle := errors.New("oracle unavailable")
+	_, err = aip11.DiscoverAccounts(entropySeed, func(n uint32, masterSeed []byte) (bool, error) {
+		return false, errOracle
+	})
+	assert.ErrorIs(t, err, errOracle, "Predicate error should be returned")
+}
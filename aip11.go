@@ -125,48 +125,30 @@ func MnemonicToEntropySeed(mnemonic []string, wordlist []string) ([]byte, error)
 
 // EntropySeedToMasterSeed derives the master seed from the entropy seed.
 func EntropySeedToMasterSeed(entropySeed []byte, customizationContext []byte) ([]byte, error) {
-	if len(entropySeed) != 32 {
-		return nil, ErrEntropySeedInvalid
-	}
-	return PRF(entropySeed, append([]byte("AccountMasterSeed"), customizationContext...)), nil
+	return softwareKeyBytes(defaultDeriver.EntropySeedToMasterSeed(SoftwareKey(entropySeed), customizationContext))
 }
 
 // MasterSeedToAccountRootSeeds derives the account root seeds from the master seed.
 func MasterSeedToAccountRootSeeds(masterSeed []byte) ([][]byte, error) {
-	if len(masterSeed) != 64 {
-		return nil, ErrMasterSeedInvalid
+	keys, err := defaultDeriver.MasterSeedToAccountRootSeeds(SoftwareKey(masterSeed))
+	if err != nil {
+		return nil, err
 	}
-
-	coinSpKeyRootSeed := PRF(masterSeed, []byte("CoinSpendKeyRootSeed"))
-	coinSnKeyRootSeed := PRF(masterSeed, []byte("CoinSerialNumberKeyRootSeed"))
-	coinDetectorRootKey := PRF(masterSeed, []byte("CoinDetectorRootKey"))
-	coinVKRootSeed := PRF(masterSeed, []byte("CoinValueKeyRootSeed"))
-	coinVKeyRootSeedAut := PRF(masterSeed, []byte("CoinValueKeyRootSeedAut"))
-
-	return [][]byte{
-		coinSpKeyRootSeed,
-		coinSnKeyRootSeed,
-		coinDetectorRootKey,
-		coinVKRootSeed,
-		coinVKeyRootSeedAut,
-	}, nil
+	rootSeeds := make([][]byte, len(keys))
+	for i, key := range keys {
+		rootSeeds[i] = key.(SoftwareKey)
+	}
+	return rootSeeds, nil
 }
 
 // MasterSeedToAccountPublicRandRootSeed derives the public rand root seed from the master seed.
 func MasterSeedToAccountPublicRandRootSeed(masterSeed []byte) ([]byte, error) {
-	if len(masterSeed) != 64 {
-		return nil, ErrMasterSeedInvalid
-	}
-	return PRF(masterSeed, []byte("PublicRandRootSeed")), nil
+	return softwareKeyBytes(defaultDeriver.MasterSeedToAccountPublicRandRootSeed(SoftwareKey(masterSeed)))
 }
 
 // DerivePublicRand derives the public rand from the public rand root seed.
 func DerivePublicRand(publicRandRootSeed []byte, index uint32) ([]byte, error) {
-	if len(publicRandRootSeed) != 64 {
-		return nil, ErrPublicRandRootSeedInvalid
-	}
-	seqNo := EncodeSeqNo(index)
-	return PRF(publicRandRootSeed, []byte(seqNo)), nil
+	return defaultDeriver.DerivePublicRand(SoftwareKey(publicRandRootSeed), index)
 }
 
 // Helper functions
@@ -269,9 +251,15 @@ func EncodeSeqNo(i uint32) string {
 	return fmt.Sprintf("%08x", i)
 }
 
+// PRFOutputSize is the size of the PRF output in bytes.
+const PRFOutputSize = 512 / 8
+
+// prfCustomizationString is the domain separation customization string of PRF.
+const prfCustomizationString = "ABELIANPRF"
+
 // PRF is the PRF function used in the seed derivation.
 func PRF(key, input []byte) []byte {
-	kmac256 := NewKMAC256(key, 512/8, []byte("ABELIANPRF"))
+	kmac256 := NewKMAC256(key, PRFOutputSize, []byte(prfCustomizationString))
 	kmac256.Write(input)
 	return kmac256.Sum(nil)
 }
@@ -0,0 +1,259 @@
+package aip11
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+// This file provides a durable sequence number allocator.
+//
+// SeqNoAllocator persists its high-water mark, the next unallocated sequence
+// number, to a file of two slots, each
+//
+//	magic "AIP11SEQ" || generation (8 bytes, big-endian) || next (8 bytes, big-endian) ||
+//	CRC-32 (IEEE) of the preceding bytes
+//
+// where the slot with the highest generation whose CRC verifies is current.
+// Generation g is always written to slot g mod 2, so an update overwrites the
+// older slot only, and a write torn by a crash leaves the current slot intact.
+//
+// Every allocation locks the file, reads the high-water mark, advances it and
+// writes it back with fsync before the lock is released, so sequence numbers
+// are never handed out twice, across goroutines or processes, nor after a
+// crash. When the high-water mark reaches 2^32 the allocator is exhausted and
+// the wallet has to move to a new account context, e.g. with AccountN.
+
+// Errors
+
+var (
+	ErrSeqNoExhausted            = errors.New("sequence numbers are exhausted")
+	ErrSeqNoCountInvalid         = errors.New("sequence number count must be positive")
+	ErrSeqNoAllocatorCorrupted   = errors.New("sequence number allocator file is corrupted")
+	ErrSeqNoAllocatorClosed      = errors.New("sequence number allocator is closed")
+	ErrSeqNoReservationFinished  = errors.New("sequence number reservation is already committed or rolled back")
+	ErrSeqNoReservationNotLatest = errors.New("sequence number reservation cannot be rolled back after later allocations")
+	ErrFileLockUnsupported       = errors.New("file locking is not supported on this platform")
+)
+
+const (
+	seqNoAllocatorMagic    = "AIP11SEQ"
+	seqNoAllocatorSlotSize = len(seqNoAllocatorMagic) + 8 + 8 + 4
+	seqNoAllocatorFileSize = 2 * seqNoAllocatorSlotSize
+
+	// seqNoSpace is the number of sequence numbers, 2^32.
+	seqNoSpace = uint64(MaxSeqNo) + 1
+)
+
+// SeqNoAllocator hands out sequence numbers backed by a file. It is safe for
+// concurrent use by multiple goroutines, and by multiple processes sharing the
+// file.
+type SeqNoAllocator struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// SeqNoReservation is a range of sequence numbers [Start, Start+Count)
+// reserved from a SeqNoAllocator. It must be finished with Commit or Rollback.
+type SeqNoReservation struct {
+	Start uint32
+	Count uint32
+
+	allocator *SeqNoAllocator
+	finished  bool
+}
+
+// OpenSeqNoAllocator opens the allocator backed by the file at path, creating
+// it with high-water mark 0 if it does not exist.
+//
+// Cross-process locking is implemented on unix and Windows only; on other
+// platforms OpenSeqNoAllocator fails with ErrFileLockUnsupported.
+func OpenSeqNoAllocator(path string) (*SeqNoAllocator, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	a := &SeqNoAllocator{file: file}
+
+	// Read the high-water mark once, to initialize a new file and to detect
+	// corruption early.
+	if _, err := a.update(func(next uint64) (uint64, error) { return next, nil }); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// HighWaterMark returns the next unallocated sequence number, or 2^32 if the
+// allocator is exhausted.
+func (a *SeqNoAllocator) HighWaterMark() (uint64, error) {
+	return a.update(func(next uint64) (uint64, error) { return next, nil })
+}
+
+// Next allocates a single sequence number.
+func (a *SeqNoAllocator) Next() (uint32, error) {
+	r, err := a.Reserve(1)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.Commit(); err != nil {
+		return 0, err
+	}
+	return r.Start, nil
+}
+
+// Reserve reserves count consecutive sequence numbers, at least one. The
+// reservation is durable as soon as Reserve returns.
+func (a *SeqNoAllocator) Reserve(count uint32) (*SeqNoReservation, error) {
+	if count == 0 {
+		return nil, ErrSeqNoCountInvalid
+	}
+	var start uint64
+	_, err := a.update(func(next uint64) (uint64, error) {
+		if next+uint64(count) > seqNoSpace {
+			return 0, ErrSeqNoExhausted
+		}
+		start = next
+		return next + uint64(count), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SeqNoReservation{Start: uint32(start), Count: count, allocator: a}, nil
+}
+
+// Close closes the backing file.
+func (a *SeqNoAllocator) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return ErrSeqNoAllocatorClosed
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// update applies f to the high-water mark under the file lock and persists the
+// result. It returns the new high-water mark.
+func (a *SeqNoAllocator) update(f func(next uint64) (uint64, error)) (uint64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return 0, ErrSeqNoAllocatorClosed
+	}
+
+	if err := lockFile(a.file); err != nil {
+		return 0, err
+	}
+	defer unlockFile(a.file)
+
+	next, generation, err := a.read()
+	if err != nil {
+		return 0, err
+	}
+	updated, err := f(next)
+	if err != nil {
+		return 0, err
+	}
+	if updated != next {
+		if err := a.write(updated, generation+1); err != nil {
+			return 0, err
+		}
+	}
+	return updated, nil
+}
+
+// read reads the high-water mark and its generation from the current slot.
+// A file in which slot 0 was never written and slot 1 is not valid has never
+// been updated successfully, and is initialized with high-water mark 0.
+func (a *SeqNoAllocator) read() (uint64, uint64, error) {
+	buf := make([]byte, seqNoAllocatorFileSize+1)
+	n, err := a.file.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return 0, 0, err
+	}
+	if n > seqNoAllocatorFileSize {
+		return 0, 0, ErrSeqNoAllocatorCorrupted
+	}
+	// Bytes past the end of a short file read as zero.
+	buf = buf[:seqNoAllocatorFileSize]
+
+	var next, generation uint64
+	found := false
+	for i := 0; i < 2; i++ {
+		slot := buf[i*seqNoAllocatorSlotSize : (i+1)*seqNoAllocatorSlotSize]
+		g, n, ok := parseSeqNoAllocatorSlot(slot)
+		if ok && g%2 == uint64(i) && (!found || g > generation) {
+			next, generation, found = n, g, true
+		}
+	}
+	if !found {
+		if !bytes.Equal(buf[:seqNoAllocatorSlotSize], make([]byte, seqNoAllocatorSlotSize)) {
+			return 0, 0, ErrSeqNoAllocatorCorrupted
+		}
+		return 0, 1, a.write(0, 1)
+	}
+	if next > seqNoSpace {
+		return 0, 0, ErrSeqNoAllocatorCorrupted
+	}
+	return next, generation, nil
+}
+
+func parseSeqNoAllocatorSlot(slot []byte) (generation, next uint64, ok bool) {
+	body := slot[:seqNoAllocatorSlotSize-4]
+	if string(body[:len(seqNoAllocatorMagic)]) != seqNoAllocatorMagic ||
+		crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(slot[seqNoAllocatorSlotSize-4:]) {
+		return 0, 0, false
+	}
+	generation = binary.BigEndian.Uint64(body[len(seqNoAllocatorMagic):])
+	next = binary.BigEndian.Uint64(body[len(seqNoAllocatorMagic)+8:])
+	return generation, next, true
+}
+
+// write persists the high-water mark as the given generation, to the slot
+// that does not hold the current one.
+func (a *SeqNoAllocator) write(next, generation uint64) error {
+	buf := make([]byte, 0, seqNoAllocatorSlotSize)
+	buf = append(buf, seqNoAllocatorMagic...)
+	buf = binary.BigEndian.AppendUint64(buf, generation)
+	buf = binary.BigEndian.AppendUint64(buf, next)
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	if _, err := a.file.WriteAt(buf, int64(generation%2)*int64(seqNoAllocatorSlotSize)); err != nil {
+		return err
+	}
+	return a.file.Sync()
+}
+
+// Commit finishes the reservation, keeping its sequence numbers allocated.
+func (r *SeqNoReservation) Commit() error {
+	if r.finished {
+		return ErrSeqNoReservationFinished
+	}
+	r.finished = true
+	return nil
+}
+
+// Rollback returns the reserved sequence numbers to the allocator. This is
+// only possible while no later allocation has been made; otherwise the
+// reservation stays allocated and ErrSeqNoReservationNotLatest is returned.
+func (r *SeqNoReservation) Rollback() error {
+	if r.finished {
+		return ErrSeqNoReservationFinished
+	}
+	end := uint64(r.Start) + uint64(r.Count)
+	_, err := r.allocator.update(func(next uint64) (uint64, error) {
+		if next != end {
+			return 0, ErrSeqNoReservationNotLatest
+		}
+		return uint64(r.Start), nil
+	})
+	if err == nil || errors.Is(err, ErrSeqNoReservationNotLatest) {
+		r.finished = true
+	}
+	return err
+}
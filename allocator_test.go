@@ -0,0 +1,211 @@
+package aip11_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleSeqNoAllocator() {
+	dir, _ := os.MkdirTemp("", "aip11")
+	defer os.RemoveAll(dir)
+
+	allocator, _ := aip11.OpenSeqNoAllocator(filepath.Join(dir, "seqno"))
+	defer allocator.Close()
+
+	first, _ := allocator.Next()
+	second, _ := allocator.Next()
+	fmt.Println(first, second)
+	// Output: 0 1
+}
+
+func TestSeqNoAllocator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seqno")
+
+	allocator, err := aip11.OpenSeqNoAllocator(path)
+	assert.NoError(t, err, "Allocator should be opened correctly")
+	seqNo, err := allocator.Next()
+	assert.NoError(t, err, "Sequence number should be allocated")
+	assert.Equal(t, uint32(0), seqNo, "First sequence number should be 0")
+
+	reservation, err := allocator.Reserve(10)
+	assert.NoError(t, err, "Sequence numbers should be reserved")
+	assert.Equal(t, uint32(1), reservation.Start, "Reservation should start after the allocation")
+	assert.NoError(t, reservation.Commit(), "Reservation should be committed")
+	assert.ErrorIs(t, reservation.Commit(), aip11.ErrSeqNoReservationFinished, "Reservation should be finished once")
+	assert.NoError(t, allocator.Close(), "Allocator should be closed")
+
+	// The high-water mark survives reopening.
+	allocator, err = aip11.OpenSeqNoAllocator(path)
+	assert.NoError(t, err, "Allocator should be reopened correctly")
+	defer allocator.Close()
+	hwm, err := allocator.HighWaterMark()
+	assert.NoError(t, err, "High-water mark should be read")
+	assert.Equal(t, uint64(11), hwm, "High-water mark should be persisted")
+}
+
+func TestSeqNoAllocatorRollback(t *testing.T) {
+	allocator, err := aip11.OpenSeqNoAllocator(filepath.Join(t.TempDir(), "seqno"))
+	assert.NoError(t, err, "Allocator should be opened correctly")
+	defer allocator.Close()
+
+	reservation, err := allocator.Reserve(5)
+	assert.NoError(t, err, "Sequence numbers should be reserved")
+	assert.NoError(t, reservation.Rollback(), "Latest reservation should be rolled back")
+	seqNo, err := allocator.Next()
+	assert.NoError(t, err, "Sequence number should be allocated")
+	assert.Equal(t, uint32(0), seqNo, "Rolled back sequence numbers should be reused")
+
+	reservation, err = allocator.Reserve(5)
+	assert.NoError(t, err, "Sequence numbers should be reserved")
+	_, err = allocator.Next()
+	assert.NoError(t, err, "Sequence number should be allocated")
+	assert.ErrorIs(t, reservation.Rollback(), aip11.ErrSeqNoReservationNotLatest, "Reservation followed by allocations should not be rolled back")
+	seqNo, err = allocator.Next()
+	assert.NoError(t, err, "Sequence number should be allocated")
+	assert.Equal(t, uint32(7), seqNo, "Reservation should stay allocated")
+}
+
+func TestSeqNoAllocatorExhaustion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seqno")
+	writeSeqNoAllocatorFile(t, path, uint64(aip11.MaxSeqNo)-1)
+
+	allocator, err := aip11.OpenSeqNoAllocator(path)
+	assert.NoError(t, err, "Allocator should be opened correctly")
+	defer allocator.Close()
+
+	_, err = allocator.Reserve(3)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoExhausted, "Reservation beyond 2^32 - 1 should be rejected")
+	_, err = allocator.Reserve(0)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoCountInvalid, "Empty reservation should be rejected")
+	reservation, err := allocator.Reserve(2)
+	assert.NoError(t, err, "Last sequence numbers should be reserved")
+	assert.Equal(t, aip11.MaxSeqNo-1, reservation.Start, "Reservation should start at the high-water mark")
+	_, err = allocator.Next()
+	assert.ErrorIs(t, err, aip11.ErrSeqNoExhausted, "Exhaustion should be signalled")
+	_, err = allocator.Reserve(0)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoCountInvalid, "Empty reservation should be rejected when exhausted")
+}
+
+func TestSeqNoAllocatorCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seqno")
+	writeSeqNoAllocatorFile(t, path, 42)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err, "Allocator file should be read")
+	data[10] ^= 1
+	data[seqNoAllocatorSlotSize+10] ^= 1
+	assert.NoError(t, os.WriteFile(path, data, 0o600), "Allocator file should be written")
+
+	_, err = aip11.OpenSeqNoAllocator(path)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoAllocatorCorrupted, "Corrupted file should be rejected")
+
+	assert.NoError(t, os.WriteFile(path, make([]byte, 2*seqNoAllocatorSlotSize+1), 0o600), "Allocator file should be written")
+	_, err = aip11.OpenSeqNoAllocator(path)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoAllocatorCorrupted, "Oversized file should be rejected")
+}
+
+func TestSeqNoAllocatorTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seqno")
+	allocator, err := aip11.OpenSeqNoAllocator(path)
+	assert.NoError(t, err, "Allocator should be opened correctly")
+	_, err = allocator.Reserve(10)
+	assert.NoError(t, err, "Sequence numbers should be reserved")
+	_, err = allocator.Reserve(5)
+	assert.NoError(t, err, "Sequence numbers should be reserved")
+	assert.NoError(t, allocator.Close(), "Allocator should be closed")
+
+	// Tear the last write, which went to the slot of generation 3.
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err, "Allocator file should be read")
+	for i := seqNoAllocatorSlotSize + 12; i < 2*seqNoAllocatorSlotSize; i++ {
+		data[i] = 0
+	}
+	assert.NoError(t, os.WriteFile(path, data, 0o600), "Allocator file should be written")
+
+	allocator, err = aip11.OpenSeqNoAllocator(path)
+	assert.NoError(t, err, "Allocator with a torn write should be opened")
+	defer allocator.Close()
+	hwm, err := allocator.HighWaterMark()
+	assert.NoError(t, err, "High-water mark should be read")
+	assert.Equal(t, uint64(10), hwm, "High-water mark should fall back to the previous write")
+
+	seqNo, err := allocator.Next()
+	assert.NoError(t, err, "Sequence number should be allocated")
+	assert.Equal(t, uint32(10), seqNo, "Allocation should continue after the previous write")
+	hwm, err = allocator.HighWaterMark()
+	assert.NoError(t, err, "High-water mark should be read")
+	assert.Equal(t, uint64(11), hwm, "High-water mark should survive overwriting the torn slot")
+}
+
+func TestSeqNoAllocatorTornInitialization(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seqno")
+	assert.NoError(t, os.WriteFile(path, append(make([]byte, seqNoAllocatorSlotSize), "AIP11S"...), 0o600), "Allocator file should be written")
+
+	allocator, err := aip11.OpenSeqNoAllocator(path)
+	assert.NoError(t, err, "Allocator whose first write was torn should be opened")
+	defer allocator.Close()
+	hwm, err := allocator.HighWaterMark()
+	assert.NoError(t, err, "High-water mark should be read")
+	assert.Equal(t, uint64(0), hwm, "High-water mark should start at 0")
+}
+
+func TestSeqNoAllocatorConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seqno")
+
+	// Two allocators on the same file stand in for two processes.
+	allocators := make([]*aip11.SeqNoAllocator, 2)
+	for i := range allocators {
+		allocator, err := aip11.OpenSeqNoAllocator(path)
+		assert.NoError(t, err, "Allocator should be opened correctly")
+		defer allocator.Close()
+		allocators[i] = allocator
+	}
+
+	const goroutines, perGoroutine = 8, 25
+	var mu sync.Mutex
+	seen := map[uint32]bool{}
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(allocator *aip11.SeqNoAllocator) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				seqNo, err := allocator.Next()
+				assert.NoError(t, err, "Sequence number should be allocated")
+				mu.Lock()
+				assert.False(t, seen[seqNo], "Sequence number %d should be allocated once", seqNo)
+				seen[seqNo] = true
+				mu.Unlock()
+			}
+		}(allocators[g%len(allocators)])
+	}
+	wg.Wait()
+
+	assert.Equal(t, goroutines*perGoroutine, len(seen), "Every allocation should be distinct")
+	hwm, err := allocators[0].HighWaterMark()
+	assert.NoError(t, err, "High-water mark should be read")
+	assert.Equal(t, uint64(goroutines*perGoroutine), hwm, "High-water mark should count every allocation")
+}
+
+const seqNoAllocatorSlotSize = 8 + 8 + 8 + 4
+
+// writeSeqNoAllocatorFile writes an allocator file whose current slot, of
+// generation 3, holds next, and whose other slot holds next-1.
+func writeSeqNoAllocatorFile(t *testing.T, path string, next uint64) {
+	buf := []byte{}
+	for generation := uint64(2); generation <= 3; generation++ {
+		slot := []byte("AIP11SEQ")
+		slot = binary.BigEndian.AppendUint64(slot, generation)
+		slot = binary.BigEndian.AppendUint64(slot, next+generation-3)
+		slot = binary.BigEndian.AppendUint32(slot, crc32.ChecksumIEEE(slot))
+		buf = append(buf, slot...)
+	}
+	assert.NoError(t, os.WriteFile(path, buf, 0o600), "Allocator file should be written")
+}
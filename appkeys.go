@@ -0,0 +1,159 @@
+package aip11
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+// This file provides application keys: purpose-bound symmetric keys derived
+// from the master seed, so that keys such as a database encryption key are
+// recoverable from the mnemonic instead of being backed up separately:
+//
+//	appKey = KMAC256(masterSeed, "AppKey" || encode_string(label), size, "AIP11AppKey")
+//
+// The customization string differs from the "ABELIANPRF" of PRF, so no
+// application key coincides with a root seed or any other PRF output, whatever
+// its label. In addition, labels used by the derivation, such as
+// "CoinSpendKeyRootSeed", are rejected to avoid confusion. KMAC binds the
+// output size, so keys of the same label and different sizes are unrelated.
+
+// Errors
+
+var (
+	ErrAppKeyLabelInvalid    = errors.New("application key label is invalid")
+	ErrAppKeyLabelReserved   = errors.New("application key label is reserved")
+	ErrAppKeyLabelRegistered = errors.New("application key label is already registered")
+	ErrAppKeyLabelUnknown    = errors.New("application key label is not registered")
+	ErrAppKeySizeInvalid     = errors.New("application key size is out of range")
+)
+
+const (
+	appKeyLabel         = "AppKey"
+	appKeyCustomization = "AIP11AppKey"
+
+	// MinAppKeySize is the minimum size of an application key in bytes.
+	MinAppKeySize = 16
+	// MaxAppKeySize is the maximum size of an application key in bytes.
+	MaxAppKeySize = 1024
+)
+
+// Labels of the predefined application keys, each 32 bytes.
+const (
+	AppKeyDatabase  = "database"
+	AppKeySync      = "sync"
+	AppKeyMessaging = "messaging"
+)
+
+// derivationLabels are the labels used by the derivation itself. Application
+// key labels and registered root seed kinds must not use them.
+var derivationLabels = []string{
+	"AccountMasterSeed",
+	"CoinSpendKeyRootSeed",
+	"CoinSerialNumberKeyRootSeed",
+	"CoinDetectorRootKey",
+	"CoinValueKeyRootSeed",
+	"CoinValueKeyRootSeedAut",
+	"PublicRandRootSeed",
+	accountIndexLabel,
+	networkMasterSeedLabel,
+	contextLabel,
+	passphraseLabel,
+	passphraseSaltLabel,
+	subMasterSeedLabel,
+	publicRandNamespaceLabel,
+	publicRandIndexTagLabel,
+	publicRandBatchKeyLabel,
+	fingerprintLabel,
+	childEntropySeedLabel,
+	appKeyLabel,
+}
+
+// DeriveAppKey derives the application key with the given label and size in
+// bytes from the master seed. Labels follow the rules of sub-master path
+// components.
+func DeriveAppKey(masterSeed []byte, label string, size int) ([]byte, error) {
+	if len(masterSeed) != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+	if err := checkAppKey(label, size); err != nil {
+		return nil, err
+	}
+
+	kmac256 := NewKMAC256(masterSeed, size, []byte(appKeyCustomization))
+	kmac256.Write([]byte(appKeyLabel))
+	kmac256.Write(encodeString([]byte(label)))
+	return kmac256.Sum(nil), nil
+}
+
+func checkAppKey(label string, size int) error {
+	if !validSubMasterPathComponent(label) {
+		return ErrAppKeyLabelInvalid
+	}
+	if slices.Contains(derivationLabels, label) {
+		return ErrAppKeyLabelReserved
+	}
+	if size < MinAppKeySize || size > MaxAppKeySize {
+		return ErrAppKeySizeInvalid
+	}
+	return nil
+}
+
+// AppKeyRegistry maps the labels of the application keys a wallet uses to
+// their sizes. It is safe for concurrent use.
+type AppKeyRegistry struct {
+	mu    sync.RWMutex
+	sizes map[string]int
+}
+
+// NewAppKeyRegistry creates a registry holding the predefined keys.
+func NewAppKeyRegistry() *AppKeyRegistry {
+	return &AppKeyRegistry{sizes: map[string]int{
+		AppKeyDatabase:  32,
+		AppKeySync:      32,
+		AppKeyMessaging: 32,
+	}}
+}
+
+// Register adds a key with the given label and size in bytes.
+func (r *AppKeyRegistry) Register(label string, size int) error {
+	if err := checkAppKey(label, size); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sizes[label]; ok {
+		return ErrAppKeyLabelRegistered
+	}
+	r.sizes[label] = size
+	return nil
+}
+
+// Size returns the size of the registered key with the given label.
+func (r *AppKeyRegistry) Size(label string) (int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	size, ok := r.sizes[label]
+	return size, ok
+}
+
+// Labels returns the labels of the registered keys in sorted order.
+func (r *AppKeyRegistry) Labels() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	labels := make([]string, 0, len(r.sizes))
+	for label := range r.sizes {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+	return labels
+}
+
+// Derive derives the registered key with the given label from the master seed.
+func (r *AppKeyRegistry) Derive(masterSeed []byte, label string) ([]byte, error) {
+	size, ok := r.Size(label)
+	if !ok {
+		return nil, ErrAppKeyLabelUnknown
+	}
+	return DeriveAppKey(masterSeed, label, size)
+}
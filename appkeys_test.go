@@ -0,0 +1,111 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleAppKeyRegistry() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+
+	registry := aip11.NewAppKeyRegistry()
+	_ = registry.Register("backup-signing", 64)
+	databaseKey, _ := registry.Derive(masterSeed, aip11.AppKeyDatabase)
+	signingKey, _ := registry.Derive(masterSeed, "backup-signing")
+	fmt.Println(registry.Labels(), len(databaseKey), len(signingKey))
+	// Output: [backup-signing database messaging sync] 32 64
+}
+
+func TestDeriveAppKey(t *testing.T) {
+	v := getAIP11Vector()[0]
+	masterSeed, err := hex.DecodeString(v.masterSeed)
+	assert.NoError(t, err, "Master seed should be decoded correctly")
+
+	testCases := []struct {
+		label string
+		size  int
+	}{
+		{label: aip11.AppKeyDatabase, size: 32},
+		{label: aip11.AppKeySync, size: 32},
+		{label: aip11.AppKeyMessaging, size: 32},
+		{label: aip11.AppKeyDatabase, size: 64},
+		{label: "custom.key_1", size: aip11.MinAppKeySize},
+		{label: "custom.key_1", size: aip11.MaxAppKeySize},
+	}
+	rootSeeds, err := aip11.MasterSeedToAccountRootSeeds(masterSeed)
+	assert.NoError(t, err, "Root seeds should be generated correctly")
+	seen := map[string]bool{}
+	for _, seed := range rootSeeds {
+		seen[hex.EncodeToString(seed)] = true
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%d", tc.label, tc.size), func(t *testing.T) {
+			key, err := aip11.DeriveAppKey(masterSeed, tc.label, tc.size)
+			assert.NoError(t, err, "Application key should be derived correctly")
+			assert.Equal(t, tc.size, len(key), "Application key should have the requested size")
+
+			kmac256 := aip11.NewKMAC256(masterSeed, tc.size, []byte("AIP11AppKey"))
+			kmac256.Write([]byte("AppKey"))
+			// encode_string(label) for labels shorter than 32 bytes.
+			kmac256.Write(append([]byte{0x01, byte(len(tc.label) * 8)}, tc.label...))
+			assert.Equal(t, kmac256.Sum(nil), key, "Application key should be derived correctly")
+
+			assert.False(t, seen[hex.EncodeToString(key)], "Application keys should be distinct from each other and from the root seeds")
+			seen[hex.EncodeToString(key)] = true
+		})
+	}
+}
+
+func TestDeriveAppKeyRejects(t *testing.T) {
+	masterSeed := make([]byte, 64)
+	for _, label := range []string{"CoinSpendKeyRootSeed", "CoinValueKeyRootSeedAut", "PublicRandRootSeed", "AccountMasterSeed", "WalletFingerprint"} {
+		_, err := aip11.DeriveAppKey(masterSeed, label, 32)
+		assert.ErrorIs(t, err, aip11.ErrAppKeyLabelReserved, fmt.Sprintf("Reserved label %s should be rejected", label))
+	}
+	_, err := aip11.DeriveAppKey(masterSeed, "", 32)
+	assert.ErrorIs(t, err, aip11.ErrAppKeyLabelInvalid, "Empty label should be rejected")
+	_, err = aip11.DeriveAppKey(masterSeed, "a b", 32)
+	assert.ErrorIs(t, err, aip11.ErrAppKeyLabelInvalid, "Malformed label should be rejected")
+	_, err = aip11.DeriveAppKey(masterSeed, aip11.AppKeySync, aip11.MinAppKeySize-1)
+	assert.ErrorIs(t, err, aip11.ErrAppKeySizeInvalid, "Short key should be rejected")
+	_, err = aip11.DeriveAppKey(masterSeed, aip11.AppKeySync, aip11.MaxAppKeySize+1)
+	assert.ErrorIs(t, err, aip11.ErrAppKeySizeInvalid, "Long key should be rejected")
+	_, err = aip11.DeriveAppKey(masterSeed[:32], aip11.AppKeySync, 32)
+	assert.ErrorIs(t, err, aip11.ErrMasterSeedInvalid, "Invalid master seed should be rejected")
+}
+
+func TestAppKeyRegistry(t *testing.T) {
+	masterSeed := make([]byte, 64)
+	registry := aip11.NewAppKeyRegistry()
+	assert.Equal(t, []string{aip11.AppKeyDatabase, aip11.AppKeyMessaging, aip11.AppKeySync}, registry.Labels(), "Predefined keys should be registered")
+
+	assert.ErrorIs(t, registry.Register(aip11.AppKeyDatabase, 64), aip11.ErrAppKeyLabelRegistered, "Label should be registered once")
+	assert.ErrorIs(t, registry.Register("CoinDetectorRootKey", 32), aip11.ErrAppKeyLabelReserved, "Reserved label should be rejected")
+	_, err := registry.Derive(masterSeed, "unknown")
+	assert.ErrorIs(t, err, aip11.ErrAppKeyLabelUnknown, "Unregistered label should be rejected")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, registry.Register(fmt.Sprintf("worker-%d", i), 48), "Key should be registered correctly")
+		}()
+	}
+	wg.Wait()
+	size, ok := registry.Size("worker-3")
+	assert.True(t, ok, "Key should be registered")
+	assert.Equal(t, 48, size, "Size should be the registered one")
+
+	key, err := registry.Derive(masterSeed, "worker-3")
+	assert.NoError(t, err, "Application key should be derived correctly")
+	expected, err := aip11.DeriveAppKey(masterSeed, "worker-3", 48)
+	assert.NoError(t, err, "Application key should be derived correctly")
+	assert.Equal(t, expected, key, "Registry should derive the same key")
+}
@@ -0,0 +1,334 @@
+package aip11
+
+import (
+	"errors"
+)
+
+// This file provides pluggable PRF backends.
+//
+// A PRFBackend evaluates PRF on keys it holds. The default backend,
+// KMACBackend, holds keys in memory as SoftwareKey and evaluates PRF with
+// NewKMAC256; the package-level derivation functions use it. Other backends
+// may hold keys elsewhere, e.g. in an HSM, and return opaque references from
+// DeriveKey so that seeds never leave the device, or may instrument or
+// accelerate the software implementation. A Deriver runs the AIP11 derivation
+// chain on any backend. The package-level functions
+//
+//	EntropySeedToMasterSeed, EntropySeedToNetworkMasterSeed, PassphraseContext,
+//	MasterSeedToAccountRootSeeds, MasterSeedToRootSeeds, MasterSeedToRootSeed,
+//	MasterSeedToAccountPublicRandRootSeed, DeriveSubMasterSeed,
+//	DerivePublicRandNamespaceRootSeed, DerivePublicRand, MasterSeedToFingerprint,
+//	DeriveChildEntropySeed and PublicRandBatchKey
+//
+// are their methods on a Deriver with KMACBackend. The other functions run in
+// software on seeds in memory and never reach a backend: DeriveAppKey, whose
+// KMAC customization differs from that of PRF, and the bulk public rand
+// functions, such as DerivePublicRandRange, PublicRands, ScanPublicRands,
+// CommitPublicRandRange, ExportPublicRandBatch and PublicRandIndex, which
+// reuse one keyed KMAC state across sequence numbers.
+
+// Errors
+
+var (
+	ErrPRFKeyUnsupported = errors.New("key is not held by this PRF backend")
+)
+
+// PRFKey is a key held by a PRFBackend.
+type PRFKey interface {
+	// Len returns the length of the key in bytes.
+	Len() int
+}
+
+// SoftwareKey is a PRFKey held in memory.
+type SoftwareKey []byte
+
+// Len returns the length of the key in bytes.
+func (k SoftwareKey) Len() int {
+	return len(k)
+}
+
+// PRFBackend evaluates PRF.
+type PRFBackend interface {
+	// PRF returns PRF(key, input).
+	PRF(key PRFKey, input []byte) ([]byte, error)
+	// DeriveKey returns PRF(key, input) as a new key held by the backend.
+	DeriveKey(key PRFKey, input []byte) (PRFKey, error)
+}
+
+// KMACBackend is the software PRF backend. Its keys are SoftwareKey.
+type KMACBackend struct{}
+
+// PRF returns PRF(key, input).
+func (KMACBackend) PRF(key PRFKey, input []byte) ([]byte, error) {
+	k, ok := key.(SoftwareKey)
+	if !ok {
+		return nil, ErrPRFKeyUnsupported
+	}
+	return PRF(k, input), nil
+}
+
+// DeriveKey returns PRF(key, input) as a SoftwareKey.
+func (b KMACBackend) DeriveKey(key PRFKey, input []byte) (PRFKey, error) {
+	output, err := b.PRF(key, input)
+	if err != nil {
+		return nil, err
+	}
+	return SoftwareKey(output), nil
+}
+
+// Deriver runs the AIP11 derivation chain on a PRF backend.
+type Deriver struct {
+	backend PRFBackend
+}
+
+// defaultDeriver backs the package-level derivation functions.
+var defaultDeriver = NewDeriver(KMACBackend{})
+
+// NewDeriver creates a Deriver on the backend.
+func NewDeriver(backend PRFBackend) *Deriver {
+	return &Deriver{backend: backend}
+}
+
+// Backend returns the backend of the Deriver.
+func (d *Deriver) Backend() PRFBackend {
+	return d.backend
+}
+
+// EntropySeedToMasterSeed derives the master seed from the entropy seed, see
+// the package-level EntropySeedToMasterSeed.
+func (d *Deriver) EntropySeedToMasterSeed(entropySeed PRFKey, customizationContext []byte) (PRFKey, error) {
+	if entropySeed.Len() != 32 {
+		return nil, ErrEntropySeedInvalid
+	}
+	return d.backend.DeriveKey(entropySeed, append([]byte("AccountMasterSeed"), customizationContext...))
+}
+
+// MasterSeedToAccountRootSeeds derives the account root seeds from the master
+// seed, see the package-level MasterSeedToAccountRootSeeds.
+func (d *Deriver) MasterSeedToAccountRootSeeds(masterSeed PRFKey) ([]PRFKey, error) {
+	if masterSeed.Len() != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+
+	// The built-in kinds, in order: CoinSpKeyRootSeed, CoinSnKeyRootSeed,
+	// CoinDetectorRootKey, CoinVKRootSeed and CoinVKeyRootSeedAut.
+	rootSeeds := make([]PRFKey, 0, len(builtinRootSeedKinds))
+	for _, kind := range builtinRootSeedKinds {
+		rootSeed, err := d.backend.DeriveKey(masterSeed, []byte(kind.Label))
+		if err != nil {
+			return nil, err
+		}
+		rootSeeds = append(rootSeeds, rootSeed)
+	}
+	return rootSeeds, nil
+}
+
+// MasterSeedToAccountPublicRandRootSeed derives the public rand root seed from
+// the master seed, see the package-level MasterSeedToAccountPublicRandRootSeed.
+func (d *Deriver) MasterSeedToAccountPublicRandRootSeed(masterSeed PRFKey) (PRFKey, error) {
+	if masterSeed.Len() != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+	return d.backend.DeriveKey(masterSeed, []byte("PublicRandRootSeed"))
+}
+
+// DerivePublicRand derives the public rand from the public rand root seed, see
+// the package-level DerivePublicRand.
+func (d *Deriver) DerivePublicRand(publicRandRootSeed PRFKey, index uint32) ([]byte, error) {
+	if publicRandRootSeed.Len() != 64 {
+		return nil, ErrPublicRandRootSeedInvalid
+	}
+	return d.backend.PRF(publicRandRootSeed, []byte(EncodeSeqNo(index)))
+}
+
+// EntropySeedToNetworkMasterSeed derives the master seed of the network from
+// the entropy seed, see the package-level EntropySeedToNetworkMasterSeed.
+func (d *Deriver) EntropySeedToNetworkMasterSeed(entropySeed PRFKey, customizationContext []byte, network Network) (PRFKey, error) {
+	if !network.Valid() {
+		return nil, ErrNetworkUnknown
+	}
+	if network == Mainnet {
+		return d.EntropySeedToMasterSeed(entropySeed, customizationContext)
+	}
+	if entropySeed.Len() != 32 {
+		return nil, ErrEntropySeedInvalid
+	}
+
+	input := []byte(networkMasterSeedLabel)
+	input = append(input, encodeString([]byte(network.String()))...)
+	input = append(input, customizationContext...)
+	return d.backend.DeriveKey(entropySeed, input)
+}
+
+// DerivedRootSeedKey is a root seed held by a PRFBackend together with its kind.
+type DerivedRootSeedKey struct {
+	Kind RootSeedKind
+	Seed PRFKey
+}
+
+// MasterSeedToRootSeeds derives the root seeds of all kinds available on the
+// network from the master seed, see the package-level MasterSeedToRootSeeds.
+func (d *Deriver) MasterSeedToRootSeeds(masterSeed PRFKey, network Network) ([]DerivedRootSeedKey, error) {
+	if masterSeed.Len() != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+	if !network.Valid() {
+		return nil, ErrNetworkUnknown
+	}
+
+	var seeds []DerivedRootSeedKey
+	for _, kind := range RootSeedKinds() {
+		if kind.Experimental && network == Mainnet {
+			continue
+		}
+		seed, err := d.backend.DeriveKey(masterSeed, []byte(kind.Label))
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, DerivedRootSeedKey{Kind: kind, Seed: seed})
+	}
+	return seeds, nil
+}
+
+// MasterSeedToRootSeed derives the root seed of the named kind from the master
+// seed for the network, see the package-level MasterSeedToRootSeed.
+func (d *Deriver) MasterSeedToRootSeed(masterSeed PRFKey, name string, network Network) (PRFKey, error) {
+	if masterSeed.Len() != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+	if !network.Valid() {
+		return nil, ErrNetworkUnknown
+	}
+	kind, ok := LookupRootSeedKind(name)
+	if !ok {
+		return nil, ErrRootSeedKindUnknown
+	}
+	if kind.Experimental && network == Mainnet {
+		return nil, ErrRootSeedKindExperimental
+	}
+	return d.backend.DeriveKey(masterSeed, []byte(kind.Label))
+}
+
+// DeriveSubMasterSeed derives the sub-master seed at path below the master
+// seed, see the package-level DeriveSubMasterSeed. For the empty path it
+// returns masterSeed itself.
+func (d *Deriver) DeriveSubMasterSeed(masterSeed PRFKey, path SubMasterPath) (PRFKey, error) {
+	if masterSeed.Len() != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+	if err := path.Validate(); err != nil {
+		return nil, err
+	}
+
+	seed := masterSeed
+	for _, component := range path {
+		input := []byte(subMasterSeedLabel)
+		input = append(input, encodeString([]byte(component))...)
+		var err error
+		if seed, err = d.backend.DeriveKey(seed, input); err != nil {
+			return nil, err
+		}
+	}
+	return seed, nil
+}
+
+// MasterSeedToFingerprint derives the fingerprint of the master seed, see the
+// package-level MasterSeedToFingerprint.
+func (d *Deriver) MasterSeedToFingerprint(masterSeed PRFKey) (Fingerprint, error) {
+	if masterSeed.Len() != 64 {
+		return Fingerprint{}, ErrMasterSeedInvalid
+	}
+	output, err := d.backend.PRF(masterSeed, []byte(fingerprintLabel))
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	var f Fingerprint
+	copy(f[:], output)
+	return f, nil
+}
+
+// DeriveChildEntropySeed derives the entropy seed of a child from the master
+// seed, see the package-level DeriveChildEntropySeed. The child entropy seed
+// is returned in the clear, to be rendered as a mnemonic.
+func (d *Deriver) DeriveChildEntropySeed(masterSeed PRFKey, application string, index uint32) ([]byte, error) {
+	if masterSeed.Len() != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+	if !validSubMasterPathComponent(application) {
+		return nil, ErrChildApplicationInvalid
+	}
+
+	input := []byte(childEntropySeedLabel)
+	input = append(input, NewContextBuilder().String(application).Uint(uint64(index)).Build()...)
+	output, err := d.backend.PRF(masterSeed, input)
+	if err != nil {
+		return nil, err
+	}
+	return output[:32], nil
+}
+
+// PublicRandBatchKey derives the key authenticating public rand batches from
+// the master seed, see the package-level PublicRandBatchKey.
+func (d *Deriver) PublicRandBatchKey(masterSeed PRFKey) ([]byte, error) {
+	if masterSeed.Len() != 64 {
+		return nil, ErrMasterSeedInvalid
+	}
+	return d.backend.PRF(masterSeed, []byte(publicRandBatchKeyLabel))
+}
+
+// PassphraseContext returns the customizationContext for the passphrase, see
+// the package-level PassphraseContext.
+func (d *Deriver) PassphraseContext(entropySeed PRFKey, passphrase string, params KDFParams) ([]byte, error) {
+	if entropySeed.Len() != 32 {
+		return nil, ErrEntropySeedInvalid
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return []byte{}, nil
+	}
+
+	normalized := NormalizePassphrase(passphrase)
+	if params.Algorithm == KDFNone {
+		return NewContextBuilder().String(passphraseLabel).String(params.String()).Bytes(normalized).Build(), nil
+	}
+
+	salt, err := d.backend.PRF(entropySeed, []byte(passphraseSaltLabel))
+	if err != nil {
+		return nil, err
+	}
+	key, err := params.Derive(normalized, salt[:32], passphraseKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	return NewContextBuilder().String(passphraseLabel).String(params.String()).Bytes(key).Build(), nil
+}
+
+// DerivePublicRandNamespaceRootSeed derives the root seed of the namespace
+// from the public rand root seed, see the package-level
+// DerivePublicRandNamespaceRootSeed. For NamespaceReceive it returns
+// publicRandRootSeed itself.
+func (d *Deriver) DerivePublicRandNamespaceRootSeed(publicRandRootSeed PRFKey, ns PublicRandNamespace) (PRFKey, error) {
+	if publicRandRootSeed.Len() != 64 {
+		return nil, ErrPublicRandRootSeedInvalid
+	}
+	if !ns.Valid() {
+		return nil, ErrPublicRandNamespaceInvalid
+	}
+	if ns == NamespaceReceive {
+		return publicRandRootSeed, nil
+	}
+
+	input := []byte(publicRandNamespaceLabel)
+	input = append(input, encodeString([]byte(ns))...)
+	return d.backend.DeriveKey(publicRandRootSeed, input)
+}
+
+// softwareKeyBytes returns the bytes of a key derived by the default backend.
+func softwareKeyBytes(key PRFKey, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return key.(SoftwareKey), nil
+}
@@ -0,0 +1,374 @@
+package aip11_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+// countingBackend records the PRF inputs evaluated by the software backend.
+type countingBackend struct {
+	aip11.KMACBackend
+	mu     sync.Mutex
+	inputs []string
+}
+
+func (b *countingBackend) record(input []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inputs = append(b.inputs, string(input))
+}
+
+func (b *countingBackend) PRF(key aip11.PRFKey, input []byte) ([]byte, error) {
+	b.record(input)
+	return b.KMACBackend.PRF(key, input)
+}
+
+func (b *countingBackend) DeriveKey(key aip11.PRFKey, input []byte) (aip11.PRFKey, error) {
+	b.record(input)
+	return b.KMACBackend.DeriveKey(key, input)
+}
+
+// handleKey is an opaque reference to a key held by handleBackend.
+type handleKey struct {
+	id     int
+	length int
+}
+
+func (k handleKey) Len() int {
+	return k.length
+}
+
+// handleBackend holds keys internally and only hands out references, as a
+// hardware backend would.
+type handleBackend struct {
+	keys [][]byte
+}
+
+func (b *handleBackend) Import(key []byte) aip11.PRFKey {
+	b.keys = append(b.keys, key)
+	return handleKey{id: len(b.keys) - 1, length: len(key)}
+}
+
+func (b *handleBackend) PRF(key aip11.PRFKey, input []byte) ([]byte, error) {
+	k, ok := key.(handleKey)
+	if !ok {
+		return nil, aip11.ErrPRFKeyUnsupported
+	}
+	return aip11.PRF(b.keys[k.id], input), nil
+}
+
+func (b *handleBackend) DeriveKey(key aip11.PRFKey, input []byte) (aip11.PRFKey, error) {
+	output, err := b.PRF(key, input)
+	if err != nil {
+		return nil, err
+	}
+	return b.Import(output), nil
+}
+
+func ExampleDeriver() {
+	backend := &countingBackend{}
+	deriver := aip11.NewDeriver(backend)
+
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := deriver.EntropySeedToMasterSeed(aip11.SoftwareKey(entropySeed), []byte{})
+	publicRandRootSeed, _ := deriver.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+	_, _ = deriver.DerivePublicRand(publicRandRootSeed, 0)
+	fmt.Println(backend.inputs[1:])
+	// Output: [PublicRandRootSeed 00000000]
+}
+
+func TestDeriver(t *testing.T) {
+	for i, v := range getAIP11Vector() {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			entropySeed, err := hex.DecodeString(v.entropySeed)
+			assert.NoError(t, err, "Entropy seed should be decoded correctly")
+
+			handles := &handleBackend{}
+			backends := map[string]struct {
+				backend aip11.PRFBackend
+				key     aip11.PRFKey
+				bytes   func(aip11.PRFKey) []byte
+			}{
+				"kmac": {
+					backend: aip11.KMACBackend{},
+					key:     aip11.SoftwareKey(entropySeed),
+					bytes:   func(k aip11.PRFKey) []byte { return k.(aip11.SoftwareKey) },
+				},
+				"handle": {
+					backend: handles,
+					key:     handles.Import(entropySeed),
+					bytes:   func(k aip11.PRFKey) []byte { return handles.keys[k.(handleKey).id] },
+				},
+			}
+			for name, b := range backends {
+				t.Run(name, func(t *testing.T) {
+					deriver := aip11.NewDeriver(b.backend)
+					assert.Equal(t, b.backend, deriver.Backend(), "Backend should be the same")
+
+					masterSeed, err := deriver.EntropySeedToMasterSeed(b.key, []byte{})
+					assert.NoError(t, err, "Master seed should be generated correctly")
+					assert.Equal(t, v.masterSeed, hex.EncodeToString(b.bytes(masterSeed)), "Master seed should be generated correctly")
+
+					rootSeeds, err := deriver.MasterSeedToAccountRootSeeds(masterSeed)
+					assert.NoError(t, err, "Root seeds should be generated correctly")
+					expected := []string{
+						v.rootSeeds.coinSpKeyRootSeed,
+						v.rootSeeds.coinSnKeyRootSeed,
+						v.rootSeeds.coinDetectorRootKey,
+						v.rootSeeds.coinVKRootSeed,
+						v.rootSeeds.coinVKeyRootSeedAut,
+					}
+					for j, rootSeed := range rootSeeds {
+						assert.Equal(t, expected[j], hex.EncodeToString(b.bytes(rootSeed)), "Root seed should be generated correctly")
+					}
+
+					publicRandRootSeed, err := deriver.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+					assert.NoError(t, err, "Public rand root seed should be generated correctly")
+					assert.Equal(t, v.publicRandRootSeed, hex.EncodeToString(b.bytes(publicRandRootSeed)), "Public rand root seed should be generated correctly")
+					for _, pr := range v.publicRands {
+						publicRand, err := deriver.DerivePublicRand(publicRandRootSeed, pr.seqNo)
+						assert.NoError(t, err, "Public rand should be generated correctly")
+						assert.Equal(t, pr.expected, hex.EncodeToString(publicRand), "Public rand should be generated correctly")
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestDeriverInstrumented(t *testing.T) {
+	backend := &countingBackend{}
+	deriver := aip11.NewDeriver(backend)
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+
+	masterSeed, err := deriver.EntropySeedToMasterSeed(aip11.SoftwareKey(entropySeed), []byte("ctx"))
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	_, err = deriver.MasterSeedToAccountRootSeeds(masterSeed)
+	assert.NoError(t, err, "Root seeds should be generated correctly")
+	assert.Equal(t, []string{
+		"AccountMasterSeedctx",
+		"CoinSpendKeyRootSeed",
+		"CoinSerialNumberKeyRootSeed",
+		"CoinDetectorRootKey",
+		"CoinValueKeyRootSeed",
+		"CoinValueKeyRootSeedAut",
+	}, backend.inputs, "Derivation should go through the backend")
+
+	expected, err := aip11.EntropySeedToMasterSeed(entropySeed, []byte("ctx"))
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	assert.Equal(t, aip11.SoftwareKey(expected), masterSeed, "Backend should match the package functions")
+}
+
+func TestDeriverRejects(t *testing.T) {
+	deriver := aip11.NewDeriver(aip11.KMACBackend{})
+	_, err := deriver.EntropySeedToMasterSeed(aip11.SoftwareKey(make([]byte, 16)), []byte{})
+	assert.ErrorIs(t, err, aip11.ErrEntropySeedInvalid, "Invalid entropy seed should be rejected")
+	_, err = deriver.MasterSeedToAccountRootSeeds(aip11.SoftwareKey(make([]byte, 32)))
+	assert.ErrorIs(t, err, aip11.ErrMasterSeedInvalid, "Invalid master seed should be rejected")
+	_, err = deriver.MasterSeedToAccountPublicRandRootSeed(aip11.SoftwareKey(make([]byte, 32)))
+	assert.ErrorIs(t, err, aip11.ErrMasterSeedInvalid, "Invalid master seed should be rejected")
+	_, err = deriver.DerivePublicRand(aip11.SoftwareKey(make([]byte, 32)), 0)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandRootSeedInvalid, "Invalid public rand root seed should be rejected")
+
+	_, err = deriver.MasterSeedToAccountRootSeeds(handleKey{length: 64})
+	assert.ErrorIs(t, err, aip11.ErrPRFKeyUnsupported, "Key of another backend should be rejected")
+}
+
+// sha3Key is a key held by sha3Backend.
+type sha3Key []byte
+
+func (k sha3Key) Len() int {
+	return len(k)
+}
+
+// sha3Backend evaluates a PRF other than KMAC, so that derivations bypassing
+// the backend give different results.
+type sha3Backend struct{}
+
+func sha3PRF(key, input []byte) []byte {
+	sum := sha3.Sum512(append(append([]byte{byte(len(key))}, key...), input...))
+	return sum[:]
+}
+
+func (sha3Backend) PRF(key aip11.PRFKey, input []byte) ([]byte, error) {
+	k, ok := key.(sha3Key)
+	if !ok {
+		return nil, aip11.ErrPRFKeyUnsupported
+	}
+	return sha3PRF(k, input), nil
+}
+
+func (b sha3Backend) DeriveKey(key aip11.PRFKey, input []byte) (aip11.PRFKey, error) {
+	output, err := b.PRF(key, input)
+	if err != nil {
+		return nil, err
+	}
+	return sha3Key(output), nil
+}
+
+func TestDeriverBackendDerivations(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	masterSeed, err := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	publicRandRootSeed, err := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+	assert.NoError(t, err, "Public rand root seed should be generated correctly")
+
+	prf := func(key []byte, input ...[]byte) []byte {
+		return sha3PRF(key, bytes.Join(input, nil))
+	}
+	params := aip11.KDFParams{Algorithm: aip11.KDFScrypt, LogN: 4, R: 1, P: 1}
+	stretched, err := params.Derive(aip11.NormalizePassphrase("passphrase"), prf(entropySeed, []byte("PassphraseSalt"))[:32], 64)
+	assert.NoError(t, err, "Passphrase should be stretched correctly")
+
+	testCases := []struct {
+		name string
+		// derive runs the derivation on the deriver, with seeds wrapped by key.
+		derive func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error)
+		// software is the package-level function.
+		software func() (any, error)
+		// expected is the output on sha3Backend.
+		expected []byte
+	}{
+		{
+			name: "network master seed",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				return d.EntropySeedToNetworkMasterSeed(key(entropySeed), []byte("ctx"), aip11.Testnet)
+			},
+			software: func() (any, error) {
+				return aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte("ctx"), aip11.Testnet)
+			},
+			expected: prf(entropySeed, []byte("NetworkAccountMasterSeed"), []byte{0x01, 0x38}, []byte("testnet"), []byte("ctx")),
+		},
+		{
+			name: "root seed",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				return d.MasterSeedToRootSeed(key(masterSeed), aip11.RootSeedVK, aip11.Mainnet)
+			},
+			software: func() (any, error) {
+				return aip11.MasterSeedToRootSeed(masterSeed, aip11.RootSeedVK, aip11.Mainnet)
+			},
+			expected: prf(masterSeed, []byte("CoinValueKeyRootSeed")),
+		},
+		{
+			name: "root seeds",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				seeds, err := d.MasterSeedToRootSeeds(key(masterSeed), aip11.Mainnet)
+				if err != nil {
+					return nil, err
+				}
+				return seeds[len(seeds)-1].Seed, nil
+			},
+			software: func() (any, error) {
+				seeds, err := aip11.MasterSeedToRootSeeds(masterSeed, aip11.Mainnet)
+				if err != nil {
+					return nil, err
+				}
+				return seeds[len(seeds)-1].Seed, nil
+			},
+			expected: prf(masterSeed, []byte("CoinValueKeyRootSeedAut")),
+		},
+		{
+			name: "sub-master seed",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				return d.DeriveSubMasterSeed(key(masterSeed), aip11.SubMasterPath{"org", "desk"})
+			},
+			software: func() (any, error) {
+				return aip11.DeriveSubMasterSeed(masterSeed, aip11.SubMasterPath{"org", "desk"})
+			},
+			expected: prf(prf(masterSeed, []byte("SubMasterSeed"), []byte{0x01, 0x18}, []byte("org")),
+				[]byte("SubMasterSeed"), []byte{0x01, 0x20}, []byte("desk")),
+		},
+		{
+			name: "fingerprint",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				f, err := d.MasterSeedToFingerprint(key(masterSeed))
+				return f[:], err
+			},
+			software: func() (any, error) {
+				f, err := aip11.MasterSeedToFingerprint(masterSeed)
+				return f[:], err
+			},
+			expected: prf(masterSeed, []byte("WalletFingerprint"))[:aip11.FingerprintSize],
+		},
+		{
+			name: "child entropy seed",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				return d.DeriveChildEntropySeed(key(masterSeed), aip11.ChildApplicationMnemonic, 7)
+			},
+			software: func() (any, error) {
+				return aip11.DeriveChildEntropySeed(masterSeed, aip11.ChildApplicationMnemonic, 7)
+			},
+			expected: prf(masterSeed, []byte("ChildEntropySeed"),
+				aip11.NewContextBuilder().String(aip11.ChildApplicationMnemonic).Uint(7).Build())[:32],
+		},
+		{
+			name: "batch key",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				return d.PublicRandBatchKey(key(masterSeed))
+			},
+			software: func() (any, error) {
+				return aip11.PublicRandBatchKey(masterSeed)
+			},
+			expected: prf(masterSeed, []byte("PublicRandBatchKey")),
+		},
+		{
+			name: "passphrase context",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				return d.PassphraseContext(key(entropySeed), "passphrase", params)
+			},
+			software: func() (any, error) {
+				return aip11.PassphraseContext(entropySeed, "passphrase", params)
+			},
+			expected: aip11.NewContextBuilder().String("Passphrase").String(params.String()).Bytes(stretched).Build(),
+		},
+		{
+			name: "namespace root seed",
+			derive: func(d *aip11.Deriver, key func([]byte) aip11.PRFKey) (any, error) {
+				return d.DerivePublicRandNamespaceRootSeed(key(publicRandRootSeed), aip11.NamespaceChange)
+			},
+			software: func() (any, error) {
+				return aip11.DerivePublicRandNamespaceRootSeed(publicRandRootSeed, aip11.NamespaceChange)
+			},
+			expected: prf(publicRandRootSeed, []byte("PublicRandNamespace"), []byte{0x01, 0x30}, []byte("change")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := tc.derive(aip11.NewDeriver(sha3Backend{}), func(b []byte) aip11.PRFKey { return sha3Key(b) })
+			assert.NoError(t, err, "Derivation should succeed on the backend")
+			assert.Equal(t, tc.expected, derivedBytes(output), "Derivation should go through the backend")
+
+			output, err = tc.derive(aip11.NewDeriver(aip11.KMACBackend{}), func(b []byte) aip11.PRFKey { return aip11.SoftwareKey(b) })
+			assert.NoError(t, err, "Derivation should succeed on the software backend")
+			expected, err := tc.software()
+			assert.NoError(t, err, "Package function should succeed")
+			assert.Equal(t, derivedBytes(expected), derivedBytes(output), "Software backend should match the package function")
+			assert.NotEqual(t, tc.expected, derivedBytes(expected), "Backends should differ")
+
+			_, err = tc.derive(aip11.NewDeriver(sha3Backend{}), func(b []byte) aip11.PRFKey { return aip11.SoftwareKey(b) })
+			assert.ErrorIs(t, err, aip11.ErrPRFKeyUnsupported, "Key of another backend should be rejected")
+		})
+	}
+}
+
+// derivedBytes returns the bytes of a derivation output, whether a key or bytes.
+func derivedBytes(output any) []byte {
+	switch o := output.(type) {
+	case sha3Key:
+		return o
+	case aip11.SoftwareKey:
+		return o
+	case []byte:
+		return o
+	}
+	return nil
+}
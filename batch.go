@@ -0,0 +1,119 @@
+package aip11
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// This file provides batch derivation of public rands.
+//
+// DerivePublicRand builds a fresh KMAC256 instance and absorbs the padded key
+// block for every sequence number. publicRandDeriver absorbs the key once and
+// clones the keyed state per sequence number, which yields the same output as
+// PRF at a fraction of the cost.
+
+// Errors
+
+var (
+	ErrSeqNoRangeInvalid        = errors.New("sequence number range exceeds 2^32 - 1")
+	ErrPublicRandBufferTooSmall = errors.New("public rand buffer is too small for the requested range")
+)
+
+const (
+	// PublicRandSize is the size of a public rand in bytes.
+	PublicRandSize = PRFOutputSize
+
+	// MaxSeqNo is the largest sequence number of a public rand.
+	MaxSeqNo = ^uint32(0)
+
+	// publicRandRangeChunkSize is the number of public rands a worker derives
+	// between two checks of the context.
+	publicRandRangeChunkSize = 256
+)
+
+// publicRandDeriver derives public rands from a public rand root seed, reusing
+// the keyed KMAC state. It is safe for concurrent use.
+type publicRandDeriver struct {
+	keyed *kmac
+}
+
+func newPublicRandDeriver(publicRandRootSeed []byte) (*publicRandDeriver, error) {
+	if len(publicRandRootSeed) != 64 {
+		return nil, ErrPublicRandRootSeedInvalid
+	}
+	keyed := NewKMAC256(publicRandRootSeed, PRFOutputSize, []byte(prfCustomizationString)).(*kmac)
+	return &publicRandDeriver{keyed: keyed}, nil
+}
+
+// derive writes the public rand with the given sequence number to dst, which
+// must have room for PublicRandSize bytes.
+func (d *publicRandDeriver) derive(index uint32, dst []byte) {
+	const digits = "0123456789abcdef"
+	var seqNo [8]byte
+	for i := 7; i >= 0; i-- {
+		seqNo[i] = digits[index&0xf]
+		index >>= 4
+	}
+
+	h := d.keyed.ShakeHash.Clone()
+	h.Write(seqNo[:])
+	h.Write(rightEncode(uint64(d.keyed.outputLen * 8)))
+	h.Read(dst[:d.keyed.outputLen])
+}
+
+// DerivePublicRandRange derives the count public rands with sequence numbers
+// start, start+1, ..., start+count-1 and writes them in order to dst, so that
+// the public rand with sequence number start+i is
+// dst[i*PublicRandSize : (i+1)*PublicRandSize]. The work is spread across
+// GOMAXPROCS goroutines. If ctx is cancelled, DerivePublicRandRange stops and
+// returns ctx.Err(), and the content of dst is unspecified. Ranges whose output
+// exceeds math.MaxInt bytes are rejected with ErrSeqNoRangeInvalid.
+func DerivePublicRandRange(ctx context.Context, publicRandRootSeed []byte, start, count uint32, dst []byte) error {
+	d, err := newPublicRandDeriver(publicRandRootSeed)
+	if err != nil {
+		return err
+	}
+	if count > 0 && uint64(start)+uint64(count)-1 > uint64(MaxSeqNo) {
+		return ErrSeqNoRangeInvalid
+	}
+	// On 32-bit platforms the output of a large range does not fit in an int.
+	if uint64(count)*PublicRandSize > math.MaxInt {
+		return ErrSeqNoRangeInvalid
+	}
+	if uint64(len(dst)) < uint64(count)*PublicRandSize {
+		return ErrPublicRandBufferTooSmall
+	}
+
+	chunks := (int(count) + publicRandRangeChunkSize - 1) / publicRandRangeChunkSize
+	workers := min(runtime.GOMAXPROCS(0), chunks)
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				chunk := int(next.Add(1) - 1)
+				if chunk >= chunks {
+					return
+				}
+				from := chunk * publicRandRangeChunkSize
+				to := min(from+publicRandRangeChunkSize, int(count))
+				for i := from; i < to; i++ {
+					d.derive(start+uint32(i), dst[i*PublicRandSize:])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
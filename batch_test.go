@@ -0,0 +1,99 @@
+package aip11_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleDerivePublicRandRange() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	publicRandRootSeed, _ := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+
+	publicRands := make([]byte, 1000*aip11.PublicRandSize)
+	err := aip11.DerivePublicRandRange(context.Background(), publicRandRootSeed, 0, 1000, publicRands)
+	fmt.Println(err)
+	// Output: <nil>
+}
+
+func TestDerivePublicRandRange(t *testing.T) {
+	vectors := getAIP11Vector()
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			publicRandRootSeed, err := hex.DecodeString(v.publicRandRootSeed)
+			assert.NoError(t, err, "Public rand root seed should be decoded correctly")
+
+			dst := make([]byte, aip11.PublicRandSize)
+			for _, publicRand := range v.publicRands {
+				err = aip11.DerivePublicRandRange(context.Background(), publicRandRootSeed, publicRand.seqNo, 1, dst)
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				assert.Equal(t, publicRand.expected, hex.EncodeToString(dst), "Public rand should be the same")
+			}
+		})
+	}
+}
+
+func TestDerivePublicRandRangeMatchesDerivePublicRand(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	for i := range publicRandRootSeed {
+		publicRandRootSeed[i] = byte(i)
+	}
+
+	testCases := []struct {
+		start uint32
+		count uint32
+	}{
+		{start: 0, count: 0},
+		{start: 0, count: 1},
+		{start: 1000, count: 3000},
+		{start: aip11.MaxSeqNo - 9, count: 10},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("start %d count %d", tc.start, tc.count), func(t *testing.T) {
+			dst := make([]byte, tc.count*aip11.PublicRandSize)
+			err := aip11.DerivePublicRandRange(context.Background(), publicRandRootSeed, tc.start, tc.count, dst)
+			assert.NoError(t, err, "Public rands should be generated correctly")
+			for i := uint32(0); i < tc.count; i++ {
+				expected, err := aip11.DerivePublicRand(publicRandRootSeed, tc.start+i)
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				if !assert.Equal(t, expected, dst[i*aip11.PublicRandSize:(i+1)*aip11.PublicRandSize], "Public rand %d should be the same", tc.start+i) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestDerivePublicRandRangeErrors(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	dst := make([]byte, 10*aip11.PublicRandSize)
+
+	err := aip11.DerivePublicRandRange(context.Background(), publicRandRootSeed[:32], 0, 10, dst)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandRootSeedInvalid, "Invalid public rand root seed should be rejected")
+
+	err = aip11.DerivePublicRandRange(context.Background(), publicRandRootSeed, 0, 11, dst)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandBufferTooSmall, "Small buffer should be rejected")
+
+	err = aip11.DerivePublicRandRange(context.Background(), publicRandRootSeed, aip11.MaxSeqNo-8, 10, dst)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoRangeInvalid, "Range beyond 2^32 - 1 should be rejected")
+
+	// The whole sequence number space only fits in an int on 64-bit platforms.
+	err = aip11.DerivePublicRandRange(context.Background(), publicRandRootSeed, 0, math.MaxUint32, dst)
+	if uint64(math.MaxUint32)*aip11.PublicRandSize > math.MaxInt {
+		assert.ErrorIs(t, err, aip11.ErrSeqNoRangeInvalid, "Range larger than an int should be rejected")
+	} else {
+		assert.ErrorIs(t, err, aip11.ErrPublicRandBufferTooSmall, "Small buffer should be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	large := make([]byte, 100000*aip11.PublicRandSize)
+	err = aip11.DerivePublicRandRange(ctx, publicRandRootSeed, 0, 100000, large)
+	assert.ErrorIs(t, err, context.Canceled, "Cancellation should be reported")
+}
@@ -0,0 +1,288 @@
+package aip11
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// This file provides a human-transcribable encoding of seeds.
+//
+// A seed is encoded as a Bech32m string [1] whose human-readable prefix names
+// the kind of seed and whose data part is
+//
+//	version (1 byte) || network (1 byte) || seed
+//
+// e.g. "abmaster1..." for a master seed. The Bech32m checksum detects
+// mistyped characters, and decoders reject strings of another kind, version or
+// network.
+//
+// BIP-173 guarantees the detection of any error affecting up to 4 characters
+// only for strings of at most 90 characters, which covers entropy seeds (71
+// characters) but not the 64-byte seeds, e.g. 121 characters for a master seed
+// and 129 for a public rand root seed. At these lengths the checksum detects any
+// error affecting up to 3 characters of the data part, which the tests verify
+// exhaustively for the longest kind; more errors go undetected with
+// probability about 2^-30.
+//
+// [1] https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+
+// Errors
+
+var (
+	ErrBech32Invalid      = errors.New("string is not valid bech32m")
+	ErrBech32Checksum     = errors.New("bech32m checksum does not match")
+	ErrBech32TooLong      = errors.New("bech32m string is too long")
+	ErrBech32MixedCase    = errors.New("bech32m string has mixed case")
+	ErrBech32InvalidChars = errors.New("bech32m string has invalid characters")
+	ErrSeedKindUnknown    = errors.New("unknown seed kind")
+	ErrSeedKindMismatch   = errors.New("encoded seed is of another kind")
+	ErrSeedVersion        = errors.New("encoded seed version is not supported")
+	ErrSeedLengthInvalid  = errors.New("seed length does not match its kind")
+)
+
+const (
+	bech32Charset  = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32mConst   = 0x2bc830a3
+	bech32MaxLen   = 1023
+	seedEncVersion = 0x01
+)
+
+// SeedKind is the kind of an encoded seed.
+type SeedKind byte
+
+const (
+	SeedKindEntropy SeedKind = iota + 1
+	SeedKindMaster
+	SeedKindSpend
+	SeedKindSerialNumber
+	SeedKindDetector
+	SeedKindVK
+	SeedKindVKAut
+	SeedKindPublicRandRoot
+)
+
+var seedKindPrefixes = map[SeedKind]string{
+	SeedKindEntropy:        "abentropy",
+	SeedKindMaster:         "abmaster",
+	SeedKindSpend:          "abspend",
+	SeedKindSerialNumber:   "absn",
+	SeedKindDetector:       "abdetector",
+	SeedKindVK:             "abvk",
+	SeedKindVKAut:          "abvkaut",
+	SeedKindPublicRandRoot: "abpublicrandroot",
+}
+
+// Prefix returns the human-readable prefix of the kind.
+func (k SeedKind) Prefix() string {
+	return seedKindPrefixes[k]
+}
+
+// String returns the human-readable prefix of the kind.
+func (k SeedKind) String() string {
+	if prefix, ok := seedKindPrefixes[k]; ok {
+		return prefix
+	}
+	return fmt.Sprintf("seedkind(%d)", byte(k))
+}
+
+// SeedLength returns the length in bytes of seeds of the kind.
+func (k SeedKind) SeedLength() int {
+	if k == SeedKindEntropy {
+		return 32
+	}
+	return 64
+}
+
+// EncodeSeed encodes the seed of the given kind for the network.
+func EncodeSeed(kind SeedKind, network Network, seed []byte) (string, error) {
+	prefix, ok := seedKindPrefixes[kind]
+	if !ok {
+		return "", ErrSeedKindUnknown
+	}
+	if !network.Valid() {
+		return "", ErrNetworkUnknown
+	}
+	if len(seed) != kind.SeedLength() {
+		return "", ErrSeedLengthInvalid
+	}
+
+	payload := make([]byte, 0, 2+len(seed))
+	payload = append(payload, seedEncVersion, byte(network))
+	payload = append(payload, seed...)
+	return bech32mEncode(prefix, convertBits(payload, 8, 5, true))
+}
+
+// DecodeSeed decodes a seed encoded by EncodeSeed, checking that it has the
+// expected kind and network.
+func DecodeSeed(s string, kind SeedKind, network Network) ([]byte, error) {
+	decodedKind, decodedNetwork, seed, err := DecodeAnySeed(s)
+	if err != nil {
+		return nil, err
+	}
+	if decodedKind != kind {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrSeedKindMismatch, kind, decodedKind)
+	}
+	if err := CheckNetwork(network, decodedNetwork); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// DecodeAnySeed decodes a seed encoded by EncodeSeed and returns its kind and
+// network.
+func DecodeAnySeed(s string) (SeedKind, Network, []byte, error) {
+	prefix, data, err := bech32mDecode(s)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	kind := SeedKind(0)
+	for k, p := range seedKindPrefixes {
+		if p == prefix {
+			kind = k
+		}
+	}
+	if kind == 0 {
+		return 0, 0, nil, ErrSeedKindUnknown
+	}
+
+	payload, ok := convertBitsStrict(data)
+	if !ok || len(payload) < 2 {
+		return 0, 0, nil, ErrBech32Invalid
+	}
+	if payload[0] != seedEncVersion {
+		return 0, 0, nil, ErrSeedVersion
+	}
+	network := Network(payload[1])
+	if !network.Valid() {
+		return 0, 0, nil, ErrNetworkUnknown
+	}
+	if len(payload)-2 != kind.SeedLength() {
+		return 0, 0, nil, ErrSeedLengthInvalid
+	}
+	return kind, network, payload[2:], nil
+}
+
+// bech32mEncode encodes the 5-bit data under the human-readable prefix.
+func bech32mEncode(hrp string, data []byte) (string, error) {
+	checksum := bech32mChecksum(hrp, data)
+	if len(hrp)+1+len(data)+len(checksum) > bech32MaxLen {
+		return "", ErrBech32TooLong
+	}
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range append(data, checksum...) {
+		sb.WriteByte(bech32Charset[d])
+	}
+	return sb.String(), nil
+}
+
+// bech32mDecode decodes a Bech32m string into its human-readable prefix and
+// 5-bit data, without the checksum.
+func bech32mDecode(s string) (string, []byte, error) {
+	if len(s) > bech32MaxLen {
+		return "", nil, ErrBech32TooLong
+	}
+	lower, upper := false, false
+	for _, c := range s {
+		if c < 33 || c > 126 {
+			return "", nil, ErrBech32InvalidChars
+		}
+		lower = lower || (c >= 'a' && c <= 'z')
+		upper = upper || (c >= 'A' && c <= 'Z')
+	}
+	if lower && upper {
+		return "", nil, ErrBech32MixedCase
+	}
+	s = strings.ToLower(s)
+
+	separator := strings.LastIndexByte(s, '1')
+	if separator < 1 || separator+7 > len(s) {
+		return "", nil, ErrBech32Invalid
+	}
+	hrp := s[:separator]
+	data := make([]byte, 0, len(s)-separator-1)
+	for _, c := range s[separator+1:] {
+		d := strings.IndexRune(bech32Charset, c)
+		if d < 0 {
+			return "", nil, ErrBech32InvalidChars
+		}
+		data = append(data, byte(d))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), data...)) != bech32mConst {
+		return "", nil, ErrBech32Checksum
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func bech32mChecksum(hrp string, data []byte) []byte {
+	values := append(bech32HRPExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	polymod := bech32Polymod(values) ^ bech32mConst
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(polymod>>(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// convertBits regroups data of fromBits-bit groups into toBits-bit groups.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(toBits-bits)&maxv))
+	}
+	return out
+}
+
+// convertBitsStrict regroups 5-bit data into bytes, rejecting non-zero or
+// overlong padding.
+func convertBitsStrict(data []byte) ([]byte, bool) {
+	bits := uint(len(data) * 5 % 8)
+	if bits >= 5 {
+		return nil, false
+	}
+	if len(data) > 0 && data[len(data)-1]&(1<<bits-1) != 0 {
+		return nil, false
+	}
+	return convertBits(data, 5, 8, false), true
+}
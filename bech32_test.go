@@ -0,0 +1,168 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleEncodeSeed() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+
+	encoded, _ := aip11.EncodeSeed(aip11.SeedKindMaster, aip11.Mainnet, masterSeed)
+	decoded, _ := aip11.DecodeSeed(encoded, aip11.SeedKindMaster, aip11.Mainnet)
+	fmt.Println(strings.HasPrefix(encoded, "abmaster1"), hex.EncodeToString(decoded) == hex.EncodeToString(masterSeed))
+	// Output: true true
+}
+
+func TestEncodeSeed(t *testing.T) {
+	v := getAIP11Vector()[0]
+	testCases := []struct {
+		kind aip11.SeedKind
+		seed string
+	}{
+		{kind: aip11.SeedKindEntropy, seed: v.entropySeed},
+		{kind: aip11.SeedKindMaster, seed: v.masterSeed},
+		{kind: aip11.SeedKindSpend, seed: v.rootSeeds.coinSpKeyRootSeed},
+		{kind: aip11.SeedKindSerialNumber, seed: v.rootSeeds.coinSnKeyRootSeed},
+		{kind: aip11.SeedKindDetector, seed: v.rootSeeds.coinDetectorRootKey},
+		{kind: aip11.SeedKindVK, seed: v.rootSeeds.coinVKRootSeed},
+		{kind: aip11.SeedKindVKAut, seed: v.rootSeeds.coinVKeyRootSeedAut},
+		{kind: aip11.SeedKindPublicRandRoot, seed: v.publicRandRootSeed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			seed, err := hex.DecodeString(tc.seed)
+			assert.NoError(t, err, "Seed should be decoded correctly")
+			encoded, err := aip11.EncodeSeed(tc.kind, aip11.Testnet, seed)
+			assert.NoError(t, err, "Seed should be encoded correctly")
+			assert.True(t, strings.HasPrefix(encoded, tc.kind.Prefix()+"1"), "Encoded seed should have the prefix of its kind")
+
+			decoded, err := aip11.DecodeSeed(encoded, tc.kind, aip11.Testnet)
+			assert.NoError(t, err, "Seed should be decoded correctly")
+			assert.Equal(t, seed, decoded, "Decoded seed should be the same")
+			decoded, err = aip11.DecodeSeed(strings.ToUpper(encoded), tc.kind, aip11.Testnet)
+			assert.NoError(t, err, "Upper-case seed should be decoded correctly")
+			assert.Equal(t, seed, decoded, "Decoded seed should be the same")
+
+			kind, network, decoded, err := aip11.DecodeAnySeed(encoded)
+			assert.NoError(t, err, "Seed should be decoded correctly")
+			assert.Equal(t, tc.kind, kind, "Kind should be the same")
+			assert.Equal(t, aip11.Testnet, network, "Network should be the same")
+			assert.Equal(t, seed, decoded, "Decoded seed should be the same")
+
+			_, err = aip11.DecodeSeed(encoded, tc.kind, aip11.Mainnet)
+			assert.ErrorIs(t, err, aip11.ErrNetworkMismatch, "Seed of another network should be rejected")
+		})
+	}
+}
+
+func TestDecodeSeedRejects(t *testing.T) {
+	v := getAIP11Vector()[0]
+	masterSeed, err := hex.DecodeString(v.masterSeed)
+	assert.NoError(t, err, "Master seed should be decoded correctly")
+	encoded, err := aip11.EncodeSeed(aip11.SeedKindMaster, aip11.Mainnet, masterSeed)
+	assert.NoError(t, err, "Seed should be encoded correctly")
+
+	_, err = aip11.DecodeSeed(encoded, aip11.SeedKindSpend, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrSeedKindMismatch, "Seed of another kind should be rejected")
+
+	// Replace a single data character.
+	i := len(encoded) - 10
+	replacement := byte('q')
+	if encoded[i] == replacement {
+		replacement = 'p'
+	}
+	mistyped := encoded[:i] + string(replacement) + encoded[i+1:]
+	_, err = aip11.DecodeSeed(mistyped, aip11.SeedKindMaster, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrBech32Checksum, "Mistyped seed should be rejected")
+
+	mixed := strings.ToUpper(encoded[:5]) + encoded[5:]
+	_, err = aip11.DecodeSeed(mixed, aip11.SeedKindMaster, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrBech32MixedCase, "Mixed-case seed should be rejected")
+
+	_, err = aip11.DecodeSeed(encoded+"b", aip11.SeedKindMaster, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrBech32InvalidChars, "Seed with invalid characters should be rejected")
+	_, err = aip11.DecodeSeed("abmaster1qqqq", aip11.SeedKindMaster, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrBech32Invalid, "Truncated seed should be rejected")
+	_, err = aip11.DecodeSeed(strings.Repeat("q", 1024), aip11.SeedKindMaster, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrBech32TooLong, "Overlong seed should be rejected")
+
+	_, err = aip11.EncodeSeed(aip11.SeedKindEntropy, aip11.Mainnet, masterSeed)
+	assert.ErrorIs(t, err, aip11.ErrSeedLengthInvalid, "Seed of the wrong length should not be encoded")
+	_, err = aip11.EncodeSeed(aip11.SeedKind(42), aip11.Mainnet, masterSeed)
+	assert.ErrorIs(t, err, aip11.ErrSeedKindUnknown, "Unknown kind should not be encoded")
+	_, err = aip11.EncodeSeed(aip11.SeedKindMaster, aip11.Network(7), masterSeed)
+	assert.ErrorIs(t, err, aip11.ErrNetworkUnknown, "Unknown network should not be encoded")
+}
+
+func TestEncodeSeedChecksumGuarantee(t *testing.T) {
+	encoded, err := aip11.EncodeSeed(aip11.SeedKindPublicRandRoot, aip11.Mainnet, make([]byte, 64))
+	assert.NoError(t, err, "Seed should be encoded correctly")
+	dataPart := len(encoded) - len(aip11.SeedKindPublicRandRoot.Prefix()) - 1
+	assert.Equal(t, 129, len(encoded), "Longest encoded seed should be 129 characters")
+
+	// Every single-character substitution is rejected by the decoder.
+	const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	for i := len(encoded) - dataPart; i < len(encoded); i++ {
+		for _, c := range []byte(charset) {
+			if c == encoded[i] {
+				continue
+			}
+			mistyped := encoded[:i] + string(c) + encoded[i+1:]
+			_, err := aip11.DecodeSeed(mistyped, aip11.SeedKindPublicRandRoot, aip11.Mainnet)
+			assert.ErrorIs(t, err, aip11.ErrBech32Checksum, "Mistyped character %d should be rejected", i)
+		}
+	}
+
+	// The checksum is linear, so an error pattern goes undetected exactly if
+	// the syndromes of its single-character errors cancel out. Check that no
+	// pattern of up to 3 errors in the data part does.
+	syndromes := make([][32]uint32, dataPart)
+	singles := map[uint32]int{}
+	for i := range syndromes {
+		for e := 1; e < 32; e++ {
+			values := make([]byte, dataPart)
+			values[i] = byte(e)
+			syndromes[i][e] = bech32Syndrome(values)
+			assert.NotZero(t, syndromes[i][e], "Single error should be detected")
+			singles[syndromes[i][e]] = i
+		}
+	}
+	assert.Equal(t, 31*dataPart, len(singles), "Any 2 errors should be detected")
+	undetected := 0
+	for i := 0; i < dataPart; i++ {
+		for j := i + 1; j < dataPart; j++ {
+			for a := 1; a < 32; a++ {
+				for b := 1; b < 32; b++ {
+					if k, ok := singles[syndromes[i][a]^syndromes[j][b]]; ok && k != i && k != j {
+						undetected++
+					}
+				}
+			}
+		}
+	}
+	assert.Zero(t, undetected, "Any 3 errors should be detected")
+}
+
+// bech32Syndrome is the BCH checksum polynomial of the values starting from 0,
+// i.e. the difference an error pattern makes to the checksum.
+func bech32Syndrome(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(0)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,231 @@
+package aip11
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// This file provides role-scoped account bundles, so that services only ever
+// hold the root seeds their role needs:
+//
+//	watch-only: CoinDetectorRootKey, PublicRandRootSeed
+//	auditor:    watch-only + CoinVKeyRootSeed, CoinVKeyRootSeedAut
+//	full spend: auditor + CoinSpKeyRootSeed, CoinSnKeyRootSeed
+//
+// A bundle is encoded as
+//
+//	magic "AIP11BDL" || version (1 byte) || role (1 byte) || network (1 byte) ||
+//	field mask (1 byte) || the 64-byte seeds present in the mask, in mask bit order ||
+//	checksum (first 4 bytes of SHA3-256 of all preceding bytes)
+//
+// where mask bit i is set if the i-th seed of spend, serialnumber, detector,
+// vk, vkaut and publicrand is present. The importer rejects a bundle whose mask
+// does not match its role exactly, so a watch-only import never yields spend
+// material.
+
+// Errors
+
+var (
+	ErrAccountBundleMalformed = errors.New("account bundle is malformed")
+	ErrAccountBundleVersion   = errors.New("account bundle version is not supported")
+	ErrAccountBundleChecksum  = errors.New("account bundle checksum does not match")
+	ErrAccountRoleUnknown     = errors.New("unknown account role")
+	ErrAccountRoleMismatch    = errors.New("account bundle fields do not match its role")
+)
+
+const (
+	accountBundleMagic        = "AIP11BDL"
+	accountBundleVersion      = 0x01
+	accountBundleChecksumSize = 4
+	accountBundleSeedSize     = 64
+)
+
+// AccountRole is the scope of an account bundle.
+type AccountRole byte
+
+const (
+	RoleWatchOnly AccountRole = 0x01
+	RoleAuditor   AccountRole = 0x02
+	RoleFullSpend AccountRole = 0x03
+)
+
+// String returns the name of the role.
+func (r AccountRole) String() string {
+	switch r {
+	case RoleWatchOnly:
+		return "watch-only"
+	case RoleAuditor:
+		return "auditor"
+	case RoleFullSpend:
+		return "full-spend"
+	default:
+		return fmt.Sprintf("role(%d)", byte(r))
+	}
+}
+
+// fieldMask returns the mask of the seeds the role holds.
+func (r AccountRole) fieldMask() (byte, error) {
+	const (
+		spend = 1 << iota
+		serialNumber
+		detector
+		vk
+		vkAut
+		publicRand
+	)
+	switch r {
+	case RoleWatchOnly:
+		return detector | publicRand, nil
+	case RoleAuditor:
+		return detector | publicRand | vk | vkAut, nil
+	case RoleFullSpend:
+		return spend | serialNumber | detector | vk | vkAut | publicRand, nil
+	default:
+		return 0, ErrAccountRoleUnknown
+	}
+}
+
+// AccountBundle holds the root seeds of an account for a role. Seeds outside
+// the role are nil.
+type AccountBundle struct {
+	Role    AccountRole
+	Network Network
+
+	CoinSpKeyRootSeed   []byte
+	CoinSnKeyRootSeed   []byte
+	CoinDetectorRootKey []byte
+	CoinVKeyRootSeed    []byte
+	CoinVKeyRootSeedAut []byte
+	PublicRandRootSeed  []byte
+}
+
+// NewAccountBundle derives the bundle of the role from the master seed.
+func NewAccountBundle(masterSeed []byte, role AccountRole, network Network) (*AccountBundle, error) {
+	mask, err := role.fieldMask()
+	if err != nil {
+		return nil, err
+	}
+	if !network.Valid() {
+		return nil, ErrNetworkUnknown
+	}
+	accountRootSeeds, err := MasterSeedToAccountRootSeeds(masterSeed)
+	if err != nil {
+		return nil, err
+	}
+	publicRandRootSeed, err := MasterSeedToAccountPublicRandRootSeed(masterSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &AccountBundle{Role: role, Network: network}
+	seeds := append(accountRootSeeds, publicRandRootSeed)
+	for i, field := range b.fields() {
+		if mask&(1<<i) != 0 {
+			*field = seeds[i]
+		}
+	}
+	return b, nil
+}
+
+// fields returns pointers to the seeds in mask bit order.
+func (b *AccountBundle) fields() []*[]byte {
+	return []*[]byte{
+		&b.CoinSpKeyRootSeed,
+		&b.CoinSnKeyRootSeed,
+		&b.CoinDetectorRootKey,
+		&b.CoinVKeyRootSeed,
+		&b.CoinVKeyRootSeedAut,
+		&b.PublicRandRootSeed,
+	}
+}
+
+// Validate checks that the bundle holds exactly the seeds of its role.
+func (b *AccountBundle) Validate() error {
+	mask, err := b.Role.fieldMask()
+	if err != nil {
+		return err
+	}
+	if !b.Network.Valid() {
+		return ErrNetworkUnknown
+	}
+	for i, field := range b.fields() {
+		present := *field != nil
+		if present != (mask&(1<<i) != 0) {
+			return ErrAccountRoleMismatch
+		}
+		if present && len(*field) != accountBundleSeedSize {
+			return ErrAccountBundleMalformed
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the bundle.
+func (b *AccountBundle) MarshalBinary() ([]byte, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	mask, _ := b.Role.fieldMask()
+
+	data := []byte(accountBundleMagic)
+	data = append(data, accountBundleVersion, byte(b.Role), byte(b.Network), mask)
+	for _, field := range b.fields() {
+		data = append(data, *field...)
+	}
+	return append(data, accountBundleChecksum(data)...), nil
+}
+
+// UnmarshalAccountBundle decodes and validates a bundle for the given network.
+// Callers that only accept some roles must check Role of the result.
+func UnmarshalAccountBundle(data []byte, network Network) (*AccountBundle, error) {
+	header := len(accountBundleMagic) + 4
+	if len(data) < header+accountBundleChecksumSize || string(data[:len(accountBundleMagic)]) != accountBundleMagic {
+		return nil, ErrAccountBundleMalformed
+	}
+	body, checksum := data[:len(data)-accountBundleChecksumSize], data[len(data)-accountBundleChecksumSize:]
+	if !bytes.Equal(checksum, accountBundleChecksum(body)) {
+		return nil, ErrAccountBundleChecksum
+	}
+	if body[len(accountBundleMagic)] != accountBundleVersion {
+		return nil, ErrAccountBundleVersion
+	}
+
+	b := &AccountBundle{
+		Role:    AccountRole(body[len(accountBundleMagic)+1]),
+		Network: Network(body[len(accountBundleMagic)+2]),
+	}
+	expectedMask, err := b.Role.fieldMask()
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckNetwork(network, b.Network); err != nil {
+		return nil, err
+	}
+	if body[len(accountBundleMagic)+3] != expectedMask {
+		return nil, ErrAccountRoleMismatch
+	}
+
+	rest := body[header:]
+	for i, field := range b.fields() {
+		if expectedMask&(1<<i) == 0 {
+			continue
+		}
+		if len(rest) < accountBundleSeedSize {
+			return nil, ErrAccountBundleMalformed
+		}
+		*field = bytes.Clone(rest[:accountBundleSeedSize])
+		rest = rest[accountBundleSeedSize:]
+	}
+	if len(rest) != 0 {
+		return nil, ErrAccountBundleMalformed
+	}
+	return b, nil
+}
+
+func accountBundleChecksum(data []byte) []byte {
+	sum := sha3.Sum256(data)
+	return sum[:accountBundleChecksumSize]
+}
@@ -0,0 +1,131 @@
+package aip11_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+func ExampleNewAccountBundle() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+
+	bundle, _ := aip11.NewAccountBundle(masterSeed, aip11.RoleWatchOnly, aip11.Mainnet)
+	data, _ := bundle.MarshalBinary()
+
+	imported, _ := aip11.UnmarshalAccountBundle(data, aip11.Mainnet)
+	fmt.Println(imported.Role, imported.CoinSpKeyRootSeed == nil)
+	// Output: watch-only true
+}
+
+func TestAccountBundle(t *testing.T) {
+	v := getAIP11Vector()[0]
+	masterSeed, err := hex.DecodeString(v.masterSeed)
+	assert.NoError(t, err, "Master seed should be decoded correctly")
+
+	encode := func(seed []byte) string {
+		if seed == nil {
+			return ""
+		}
+		return hex.EncodeToString(seed)
+	}
+	testCases := []struct {
+		role     aip11.AccountRole
+		expected [6]string
+	}{
+		{role: aip11.RoleWatchOnly, expected: [6]string{"", "", v.rootSeeds.coinDetectorRootKey, "", "", v.publicRandRootSeed}},
+		{role: aip11.RoleAuditor, expected: [6]string{"", "", v.rootSeeds.coinDetectorRootKey, v.rootSeeds.coinVKRootSeed, v.rootSeeds.coinVKeyRootSeedAut, v.publicRandRootSeed}},
+		{role: aip11.RoleFullSpend, expected: [6]string{v.rootSeeds.coinSpKeyRootSeed, v.rootSeeds.coinSnKeyRootSeed, v.rootSeeds.coinDetectorRootKey, v.rootSeeds.coinVKRootSeed, v.rootSeeds.coinVKeyRootSeedAut, v.publicRandRootSeed}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.role.String(), func(t *testing.T) {
+			bundle, err := aip11.NewAccountBundle(masterSeed, tc.role, aip11.Testnet)
+			assert.NoError(t, err, "Bundle should be created correctly")
+			data, err := bundle.MarshalBinary()
+			assert.NoError(t, err, "Bundle should be encoded correctly")
+
+			imported, err := aip11.UnmarshalAccountBundle(data, aip11.Testnet)
+			assert.NoError(t, err, "Bundle should be imported correctly")
+			assert.Equal(t, bundle, imported, "Imported bundle should be the same")
+			actual := [6]string{
+				encode(imported.CoinSpKeyRootSeed),
+				encode(imported.CoinSnKeyRootSeed),
+				encode(imported.CoinDetectorRootKey),
+				encode(imported.CoinVKeyRootSeed),
+				encode(imported.CoinVKeyRootSeedAut),
+				encode(imported.PublicRandRootSeed),
+			}
+			assert.Equal(t, tc.expected, actual, "Bundle should hold exactly the seeds of the role")
+
+			_, err = aip11.UnmarshalAccountBundle(data, aip11.Mainnet)
+			assert.ErrorIs(t, err, aip11.ErrNetworkMismatch, "Bundle of another network should be rejected")
+		})
+	}
+}
+
+func TestUnmarshalAccountBundleRejects(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	masterSeed, err := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	bundle, err := aip11.NewAccountBundle(masterSeed, aip11.RoleWatchOnly, aip11.Mainnet)
+	assert.NoError(t, err, "Bundle should be created correctly")
+	data, err := bundle.MarshalBinary()
+	assert.NoError(t, err, "Bundle should be encoded correctly")
+
+	corrupted := bytes.Clone(data)
+	corrupted[20] ^= 1
+	_, err = aip11.UnmarshalAccountBundle(corrupted, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrAccountBundleChecksum, "Corrupted bundle should be rejected")
+
+	_, err = aip11.UnmarshalAccountBundle(data[:6], aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrAccountBundleMalformed, "Truncated bundle should be rejected")
+
+	// A watch-only bundle smuggling spend material is rejected even with a
+	// valid checksum.
+	full, err := aip11.NewAccountBundle(masterSeed, aip11.RoleFullSpend, aip11.Mainnet)
+	assert.NoError(t, err, "Bundle should be created correctly")
+	fullData, err := full.MarshalBinary()
+	assert.NoError(t, err, "Bundle should be encoded correctly")
+	forged := bytes.Clone(fullData[:len(fullData)-4])
+	forged[9] = byte(aip11.RoleWatchOnly)
+	forged = appendBundleChecksum(forged)
+	_, err = aip11.UnmarshalAccountBundle(forged, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrAccountRoleMismatch, "Bundle with seeds outside its role should be rejected")
+
+	unknownRole := bytes.Clone(data[:len(data)-4])
+	unknownRole[9] = 0x7f
+	unknownRole = appendBundleChecksum(unknownRole)
+	_, err = aip11.UnmarshalAccountBundle(unknownRole, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrAccountRoleUnknown, "Unknown role should be rejected")
+
+	bundle.CoinSpKeyRootSeed = make([]byte, 64)
+	_, err = bundle.MarshalBinary()
+	assert.ErrorIs(t, err, aip11.ErrAccountRoleMismatch, "Watch-only bundle with spend material should not be encoded")
+
+	_, err = aip11.NewAccountBundle(masterSeed, aip11.AccountRole(9), aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrAccountRoleUnknown, "Unknown role should be rejected")
+	_, err = aip11.NewAccountBundle(masterSeed[:32], aip11.RoleAuditor, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrMasterSeedInvalid, "Invalid master seed should be rejected")
+}
+
+func appendBundleChecksum(body []byte) []byte {
+	sum := sha3.Sum256(body)
+	return append(body, sum[:4]...)
+}
+
+func TestAccountRoleString(t *testing.T) {
+	for role, expected := range map[aip11.AccountRole]string{
+		aip11.RoleWatchOnly:  "watch-only",
+		aip11.RoleAuditor:    "auditor",
+		aip11.RoleFullSpend:  "full-spend",
+		aip11.AccountRole(9): "role(9)",
+	} {
+		assert.Equal(t, expected, role.String(), fmt.Sprintf("Role %d should be named correctly", role))
+	}
+}
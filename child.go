@@ -0,0 +1,46 @@
+package aip11
+
+import (
+	"errors"
+)
+
+// This file provides child mnemonics, in the spirit of BIP-85: one backed-up
+// mnemonic derives independent mnemonics for other wallets or devices. The
+// entropy seed of a child is
+//
+//	childEntropySeed = PRF(masterSeed, "ChildEntropySeed" ||
+//	                       NewContextBuilder().String(application).Uint(index).Build())[:32]
+//
+// and is rendered as a mnemonic with EntropySeedToMnemonic as usual. A child
+// is reproducible from its parent's master seed, the application and the index,
+// and, PRF being one-way, reveals nothing about the parent or its siblings.
+// The child is a complete wallet of its own, with its own master seed.
+
+// Errors
+
+var (
+	ErrChildApplicationInvalid = errors.New("child application name is invalid")
+)
+
+const childEntropySeedLabel = "ChildEntropySeed"
+
+// ChildApplicationMnemonic is the application name for general-purpose child
+// wallets.
+const ChildApplicationMnemonic = "mnemonic"
+
+// DeriveChildEntropySeed derives the entropy seed of the child with the given
+// application and index from the master seed. Application names follow the
+// rules of sub-master path components.
+func DeriveChildEntropySeed(masterSeed []byte, application string, index uint32) ([]byte, error) {
+	return defaultDeriver.DeriveChildEntropySeed(SoftwareKey(masterSeed), application, index)
+}
+
+// DeriveChildMnemonic derives the mnemonic of the child with the given
+// application and index from the master seed.
+func DeriveChildMnemonic(masterSeed []byte, application string, index uint32, wordlist []string) ([]string, error) {
+	childEntropySeed, err := DeriveChildEntropySeed(masterSeed, application, index)
+	if err != nil {
+		return nil, err
+	}
+	return EntropySeedToMnemonic(childEntropySeed, wordlist)
+}
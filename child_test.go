@@ -0,0 +1,71 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/pqabelian/abelian-aip11-go/wordlists"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleDeriveChildMnemonic() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+
+	mnemonic, _ := aip11.DeriveChildMnemonic(masterSeed, aip11.ChildApplicationMnemonic, 0, wordlists.English)
+	childEntropySeed, _ := aip11.MnemonicToEntropySeed(mnemonic, wordlists.English)
+	fmt.Println(len(mnemonic), len(childEntropySeed))
+	// Output: 24 32
+}
+
+func TestDeriveChildEntropySeed(t *testing.T) {
+	v := getAIP11Vector()[0]
+	masterSeed, err := hex.DecodeString(v.masterSeed)
+	assert.NoError(t, err, "Master seed should be decoded correctly")
+
+	testCases := []struct {
+		application string
+		index       uint32
+	}{
+		{application: aip11.ChildApplicationMnemonic, index: 0},
+		{application: aip11.ChildApplicationMnemonic, index: 1},
+		{application: "family", index: 0},
+		{application: "hardware-wallet", index: 7},
+	}
+	seen := map[string]bool{}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%d", tc.application, tc.index), func(t *testing.T) {
+			child, err := aip11.DeriveChildEntropySeed(masterSeed, tc.application, tc.index)
+			assert.NoError(t, err, "Child entropy seed should be derived correctly")
+			assert.Equal(t, 32, len(child), "Child entropy seed should be 32 bytes")
+
+			input := append([]byte("ChildEntropySeed"), aip11.NewContextBuilder().String(tc.application).Uint(uint64(tc.index)).Build()...)
+			assert.Equal(t, aip11.PRF(masterSeed, input)[:32], child, "Child entropy seed should be derived correctly")
+			assert.False(t, seen[hex.EncodeToString(child)], "Children should be independent")
+			seen[hex.EncodeToString(child)] = true
+
+			again, err := aip11.DeriveChildEntropySeed(masterSeed, tc.application, tc.index)
+			assert.NoError(t, err, "Child entropy seed should be derived correctly")
+			assert.Equal(t, child, again, "Child entropy seed should be reproducible")
+
+			mnemonic, err := aip11.DeriveChildMnemonic(masterSeed, tc.application, tc.index, wordlists.English)
+			assert.NoError(t, err, "Child mnemonic should be derived correctly")
+			expected, err := aip11.EntropySeedToMnemonic(child, wordlists.English)
+			assert.NoError(t, err, "Mnemonic should be generated correctly")
+			assert.Equal(t, expected, mnemonic, "Child mnemonic should render the child entropy seed")
+		})
+	}
+}
+
+func TestDeriveChildEntropySeedRejects(t *testing.T) {
+	_, err := aip11.DeriveChildEntropySeed(make([]byte, 32), aip11.ChildApplicationMnemonic, 0)
+	assert.ErrorIs(t, err, aip11.ErrMasterSeedInvalid, "Invalid master seed should be rejected")
+	_, err = aip11.DeriveChildEntropySeed(make([]byte, 64), "", 0)
+	assert.ErrorIs(t, err, aip11.ErrChildApplicationInvalid, "Empty application should be rejected")
+	_, err = aip11.DeriveChildEntropySeed(make([]byte, 64), "a/b", 0)
+	assert.ErrorIs(t, err, aip11.ErrChildApplicationInvalid, "Malformed application should be rejected")
+	_, err = aip11.DeriveChildMnemonic(make([]byte, 64), aip11.ChildApplicationMnemonic, 0, wordlists.English[:5])
+	assert.ErrorIs(t, err, aip11.ErrWordlistLengthInvalid, "Invalid wordlist should be rejected")
+}
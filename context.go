@@ -0,0 +1,162 @@
+package aip11
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// This file provides a canonical encoding for structured customizationContext.
+//
+// EntropySeedToMasterSeed derives the master seed from
+// "AccountMasterSeed" || customizationContext, so contexts assembled by plain
+// concatenation of several fields may collide, e.g. ("ab", "c") and ("a", "bc").
+// ContextBuilder encodes each field as its type followed by encode_string as
+// specified in 2.3.2 of [1], in the style of TupleHash (section 5 of [1]).
+// The encoding is injective: two different field lists never produce the same
+// context, so accounts derived from different field lists never share a
+// master seed.
+//
+// [1] https://nvlpubs.nist.gov/nistpubs/SpecialPublications/NIST.SP.800-185.pdf
+
+// Errors
+
+var (
+	ErrContextMalformed = errors.New("customization context is not a structured context")
+)
+
+// ContextFieldType is the type tag of a structured context field.
+type ContextFieldType byte
+
+const (
+	ContextFieldString ContextFieldType = 0x01
+	ContextFieldUint   ContextFieldType = 0x02
+	ContextFieldBytes  ContextFieldType = 0x03
+)
+
+// contextLabel prefixes every structured context, so that structured contexts
+// are distinguishable from free-form ones.
+const contextLabel = "AIP11StructuredContext"
+
+// ContextField is a single typed field of a structured context.
+type ContextField struct {
+	Type  ContextFieldType
+	Value []byte
+}
+
+// ContextBuilder builds a structured customizationContext.
+type ContextBuilder struct {
+	fields []ContextField
+}
+
+// NewContextBuilder creates an empty ContextBuilder.
+func NewContextBuilder() *ContextBuilder {
+	return &ContextBuilder{}
+}
+
+// String appends a string field.
+func (b *ContextBuilder) String(s string) *ContextBuilder {
+	return b.append(ContextFieldString, []byte(s))
+}
+
+// Uint appends an unsigned integer field, encoded as 8 bytes big-endian.
+func (b *ContextBuilder) Uint(v uint64) *ContextBuilder {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, v)
+	return b.append(ContextFieldUint, value)
+}
+
+// Bytes appends a byte string field.
+func (b *ContextBuilder) Bytes(v []byte) *ContextBuilder {
+	return b.append(ContextFieldBytes, v)
+}
+
+func (b *ContextBuilder) append(fieldType ContextFieldType, value []byte) *ContextBuilder {
+	v := make([]byte, len(value))
+	copy(v, value)
+	b.fields = append(b.fields, ContextField{Type: fieldType, Value: v})
+	return b
+}
+
+// Fields returns the fields appended so far.
+func (b *ContextBuilder) Fields() []ContextField {
+	return b.fields
+}
+
+// Build returns the encoded customizationContext:
+//
+//	encode_string(contextLabel) || type_1 || encode_string(value_1) || ... || type_n || encode_string(value_n)
+func (b *ContextBuilder) Build() []byte {
+	context := encodeString([]byte(contextLabel))
+	for _, field := range b.fields {
+		context = append(context, byte(field.Type))
+		context = append(context, encodeString(field.Value)...)
+	}
+	return context
+}
+
+// ParseContext decodes a customizationContext produced by ContextBuilder.Build.
+func ParseContext(context []byte) ([]ContextField, error) {
+	label, rest, err := decodeString(context)
+	if err != nil || string(label) != contextLabel {
+		return nil, ErrContextMalformed
+	}
+
+	fields := []ContextField{}
+	for len(rest) > 0 {
+		fieldType := ContextFieldType(rest[0])
+		var value []byte
+		value, rest, err = decodeString(rest[1:])
+		if err != nil {
+			return nil, err
+		}
+		switch fieldType {
+		case ContextFieldString, ContextFieldBytes:
+		case ContextFieldUint:
+			if len(value) != 8 {
+				return nil, ErrContextMalformed
+			}
+		default:
+			return nil, ErrContextMalformed
+		}
+		fields = append(fields, ContextField{Type: fieldType, Value: value})
+	}
+	return fields, nil
+}
+
+// encodeString encodes the bit string S so that it can be unambiguously parsed
+// from the beginning of the result.
+//
+// specified in 2.3.2 of [1].
+func encodeString(s []byte) []byte {
+	encoded := leftEncode(uint64(len(s)) * 8)
+	return append(encoded, s...)
+}
+
+// decodeString is the inverse of encodeString. It returns the decoded string
+// and the remaining input.
+func decodeString(input []byte) ([]byte, []byte, error) {
+	if len(input) == 0 {
+		return nil, nil, ErrContextMalformed
+	}
+	n := int(input[0])
+	if n < 1 || n > 8 || len(input) < 1+n {
+		return nil, nil, ErrContextMalformed
+	}
+	var bitLength uint64
+	for _, b := range input[1 : 1+n] {
+		bitLength = bitLength<<8 | uint64(b)
+	}
+	if bitLength%8 != 0 {
+		return nil, nil, ErrContextMalformed
+	}
+	rest := input[1+n:]
+	if bitLength/8 > uint64(len(rest)) {
+		return nil, nil, ErrContextMalformed
+	}
+	length := int(bitLength / 8)
+	// Reject non-minimal length encodings, which would break injectivity.
+	if string(leftEncode(bitLength)) != string(input[:1+n]) {
+		return nil, nil, ErrContextMalformed
+	}
+	return rest[:length], rest[length:], nil
+}
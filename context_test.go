@@ -0,0 +1,71 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleContextBuilder() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	context := aip11.NewContextBuilder().String("exchange").String("customer-42").Uint(0).Build()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, context)
+	fmt.Println(len(masterSeed))
+	// Output: 64
+}
+
+func TestContextBuilder(t *testing.T) {
+	context := aip11.NewContextBuilder().String("ab").Uint(1).Bytes([]byte{0xff}).Build()
+	expected := "01b0" + hex.EncodeToString([]byte("AIP11StructuredContext")) +
+		"01" + "0110" + hex.EncodeToString([]byte("ab")) +
+		"02" + "0140" + "0000000000000001" +
+		"03" + "0108" + "ff"
+	assert.Equal(t, expected, hex.EncodeToString(context), "Context should be encoded correctly")
+}
+
+func TestContextBuilderNoCollision(t *testing.T) {
+	contexts := [][]byte{
+		aip11.NewContextBuilder().Build(),
+		aip11.NewContextBuilder().String("").Build(),
+		aip11.NewContextBuilder().String("ab").String("c").Build(),
+		aip11.NewContextBuilder().String("a").String("bc").Build(),
+		aip11.NewContextBuilder().String("abc").Build(),
+		aip11.NewContextBuilder().Bytes([]byte("abc")).Build(),
+		aip11.NewContextBuilder().String("app").String("user").Uint(1).Build(),
+		aip11.NewContextBuilder().String("app").String("user1").Build(),
+	}
+
+	seen := map[string]int{}
+	for i, context := range contexts {
+		j, ok := seen[string(context)]
+		assert.False(t, ok, "Context %d should not collide with context %d", i, j)
+		seen[string(context)] = i
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	builder := aip11.NewContextBuilder().String("app").Uint(7).Bytes([]byte{})
+	fields, err := aip11.ParseContext(builder.Build())
+	assert.NoError(t, err, "Context should be parsed correctly")
+	assert.Equal(t, builder.Fields(), fields, "Parsed fields should be the same")
+
+	testCases := []struct {
+		name    string
+		context []byte
+	}{
+		{name: "Empty context", context: []byte{}},
+		{name: "Free-form context", context: []byte("treasury")},
+		{name: "Truncated field", context: aip11.NewContextBuilder().String("app").Build()[:27]},
+		{name: "Unknown field type", context: append(aip11.NewContextBuilder().Build(), 0x7f, 0x01, 0x00)},
+		{name: "Short uint field", context: append(aip11.NewContextBuilder().Build(), 0x02, 0x01, 0x08, 0x01)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := aip11.ParseContext(tc.context)
+			assert.ErrorIs(t, err, aip11.ErrContextMalformed, "Malformed context should be rejected")
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package aip11
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// This file provides account descriptors, which capture everything besides
+// the seed needed to restore an account, e.g.
+//
+//	aip11desc(profile=aip15,net=testnet,ctx=treasury,types=1+2+3,ns=change,depth=20)#gmpd4d4z
+//
+// The options, separated by ',', are
+//
+//	profile=<profile>  legacy, aip11 or aip15
+//	net=<network>      network, see EntropySeedToNetworkMasterSeed
+//	ctx=<context>      customizationContext, as text or as 0x-prefixed hex (default empty)
+//	types=<t>+<t>...   allowed address types, by number
+//	ns=<namespace>     public rand namespace (default the receive namespace)
+//	depth=<n>          gap limit of the public rand scan
+//
+// String writes the options in this order and omits ctx and ns when they are
+// the default. The descriptor ends with '#' and the 8-character checksum of
+// output script descriptors [1] over the body before '#'. Errors are counted in
+// symbols: a case error, or a substitution among the characters
+// 0-9 ( ) [ ] , ' / * a-h @ : $ % { }, is one symbol error, and any other
+// substitution one or two. The body is at most MaxDescriptorBodyLength
+// characters, for which the checksum detects any 4 symbol errors; other errors
+// go undetected with probability about 2^-40.
+//
+// The legacy profile is AbewalletMLP-v1.0.1, which has no customizationContext
+// and no namespaces. Address type 3 needs CoinVKeyRootSeedAut, which only the
+// aip15 profile derives.
+//
+// [1] https://github.com/bitcoin/bips/blob/master/bip-0380.mediawiki
+
+// Errors
+
+var (
+	ErrDescriptorInvalid  = errors.New("account descriptor is invalid")
+	ErrDescriptorChecksum = errors.New("account descriptor checksum does not match")
+	ErrDescriptorTooLong  = errors.New("account descriptor is too long")
+	ErrProfileUnknown     = errors.New("unknown profile")
+	ErrProfileUnsupported = errors.New("profile is not supported by this operation")
+	ErrAddressTypeUnknown = errors.New("unknown address type")
+)
+
+const (
+	descriptorPrefix       = "aip11desc("
+	descriptorChecksumSize = 8
+
+	// MaxDescriptorBodyLength is the maximum length of a descriptor without its
+	// checksum, up to which BIP-380 guarantees the detection of 4 errors.
+	MaxDescriptorBodyLength = 507
+
+	// descriptorInputCharset orders the printable ASCII characters so that the
+	// common ones differ in their low 5 bits only, as in BIP-380.
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+)
+
+// Profile is the specification an account is derived under.
+type Profile byte
+
+const (
+	// ProfileLegacy is AbewalletMLP-v1.0.1.
+	ProfileLegacy Profile = 0x01
+	// ProfileAIP11 derives the four root seeds of AIP11.
+	ProfileAIP11 Profile = 0x02
+	// ProfileAIP15 additionally derives CoinVKeyRootSeedAut.
+	ProfileAIP15 Profile = 0x03
+)
+
+// String returns the name of the profile.
+func (p Profile) String() string {
+	switch p {
+	case ProfileLegacy:
+		return "legacy"
+	case ProfileAIP11:
+		return "aip11"
+	case ProfileAIP15:
+		return "aip15"
+	default:
+		return fmt.Sprintf("profile(%d)", byte(p))
+	}
+}
+
+// Valid reports whether p is a known profile.
+func (p Profile) Valid() bool {
+	return p == ProfileLegacy || p == ProfileAIP11 || p == ProfileAIP15
+}
+
+// ParseProfile parses the name of a profile as returned by Profile.String.
+func ParseProfile(s string) (Profile, error) {
+	switch s {
+	case "legacy":
+		return ProfileLegacy, nil
+	case "aip11":
+		return ProfileAIP11, nil
+	case "aip15":
+		return ProfileAIP15, nil
+	default:
+		return 0, ErrProfileUnknown
+	}
+}
+
+// AddressType is an Abelian address type.
+type AddressType byte
+
+const (
+	// AddressTypeFullPrivacy uses CoinSpKeyRootSeed, CoinSnKeyRootSeed,
+	// CoinDetectorRootKey and CoinVKeyRootSeed.
+	AddressTypeFullPrivacy AddressType = 0x01
+	// AddressTypePseudonym uses CoinSpKeyRootSeed and CoinDetectorRootKey.
+	AddressTypePseudonym AddressType = 0x02
+	// AddressTypePseudonymCT uses CoinSpKeyRootSeed, CoinDetectorRootKey and
+	// CoinVKeyRootSeedAut.
+	AddressTypePseudonymCT AddressType = 0x03
+)
+
+// String returns the name of the address type.
+func (t AddressType) String() string {
+	switch t {
+	case AddressTypeFullPrivacy:
+		return "full-privacy"
+	case AddressTypePseudonym:
+		return "pseudonym"
+	case AddressTypePseudonymCT:
+		return "pseudonym-ct"
+	default:
+		return fmt.Sprintf("addresstype(%d)", byte(t))
+	}
+}
+
+// Valid reports whether t is a known address type.
+func (t AddressType) Valid() bool {
+	return t == AddressTypeFullPrivacy || t == AddressTypePseudonym || t == AddressTypePseudonymCT
+}
+
+// AccountDescriptor is the derivation configuration of an account.
+type AccountDescriptor struct {
+	Profile      Profile
+	Network      Network
+	Context      []byte
+	AddressTypes []AddressType // in increasing order, without duplicates
+	Namespace    PublicRandNamespace
+	ScanDepth    uint32
+}
+
+// Validate checks that the descriptor is consistent.
+func (d *AccountDescriptor) Validate() error {
+	if !d.Profile.Valid() {
+		return ErrProfileUnknown
+	}
+	if !d.Network.Valid() {
+		return ErrNetworkUnknown
+	}
+	if !d.Namespace.Valid() {
+		return ErrPublicRandNamespaceInvalid
+	}
+	if d.Profile == ProfileLegacy && (len(d.Context) != 0 || d.Namespace != NamespaceReceive) {
+		return ErrDescriptorInvalid
+	}
+	if len(d.AddressTypes) == 0 || d.ScanDepth == 0 {
+		return ErrDescriptorInvalid
+	}
+	for i, t := range d.AddressTypes {
+		if !t.Valid() {
+			return ErrAddressTypeUnknown
+		}
+		if i > 0 && t <= d.AddressTypes[i-1] {
+			return ErrDescriptorInvalid
+		}
+		if t == AddressTypePseudonymCT && d.Profile != ProfileAIP15 {
+			return ErrDescriptorInvalid
+		}
+	}
+	if len(d.body()) > MaxDescriptorBodyLength {
+		return ErrDescriptorTooLong
+	}
+	return nil
+}
+
+// String returns the canonical form of the descriptor with its checksum. The
+// descriptor must be valid.
+func (d *AccountDescriptor) String() string {
+	body := d.body()
+	return body + "#" + DescriptorChecksum(body)
+}
+
+// body returns the canonical form of the descriptor without its checksum.
+func (d *AccountDescriptor) body() string {
+	options := []string{
+		"profile=" + d.Profile.String(),
+		"net=" + d.Network.String(),
+	}
+	if len(d.Context) != 0 {
+		options = append(options, "ctx="+formatPathContext(d.Context))
+	}
+	types := make([]string, len(d.AddressTypes))
+	for i, t := range d.AddressTypes {
+		types[i] = strconv.Itoa(int(t))
+	}
+	options = append(options, "types="+strings.Join(types, "+"))
+	if d.Namespace != NamespaceReceive {
+		options = append(options, "ns="+string(d.Namespace))
+	}
+	options = append(options, "depth="+strconv.FormatUint(uint64(d.ScanDepth), 10))
+
+	return descriptorPrefix + strings.Join(options, ",") + ")"
+}
+
+// MasterSeed derives the master seed of the account from the entropy seed.
+// The legacy profile is not supported.
+func (d *AccountDescriptor) MasterSeed(entropySeed []byte) ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	if d.Profile == ProfileLegacy {
+		return nil, ErrProfileUnsupported
+	}
+	return EntropySeedToNetworkMasterSeed(entropySeed, d.Context, d.Network)
+}
+
+// ParseAccountDescriptor parses and validates a descriptor, verifying its
+// checksum. Options may appear in any order but at most once.
+func ParseAccountDescriptor(s string) (*AccountDescriptor, error) {
+	body, checksum, ok := strings.Cut(s, "#")
+	if !ok || len(checksum) != descriptorChecksumSize {
+		return nil, ErrDescriptorInvalid
+	}
+	if len(body) > MaxDescriptorBodyLength {
+		return nil, ErrDescriptorTooLong
+	}
+	for _, c := range body {
+		if c < 33 || c > 126 {
+			return nil, ErrDescriptorInvalid
+		}
+	}
+	if checksum != DescriptorChecksum(body) {
+		return nil, ErrDescriptorChecksum
+	}
+	inner, ok := strings.CutPrefix(body, descriptorPrefix)
+	if !ok {
+		return nil, ErrDescriptorInvalid
+	}
+	inner, ok = strings.CutSuffix(inner, ")")
+	if !ok {
+		return nil, ErrDescriptorInvalid
+	}
+
+	d := &AccountDescriptor{Context: []byte{}}
+	seen := map[string]bool{}
+	for _, option := range strings.Split(inner, ",") {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok || seen[key] {
+			return nil, ErrDescriptorInvalid
+		}
+		seen[key] = true
+		var err error
+		switch key {
+		case "profile":
+			d.Profile, err = ParseProfile(value)
+		case "net":
+			d.Network, err = ParseNetwork(value)
+		case "ctx":
+			d.Context, err = parsePathContext(value)
+		case "types":
+			d.AddressTypes, err = parseAddressTypes(value)
+		case "ns":
+			d.Namespace = PublicRandNamespace(value)
+			if d.Namespace == NamespaceReceive {
+				err = ErrDescriptorInvalid
+			}
+		case "depth":
+			d.ScanDepth, err = parsePathUint32(value)
+		default:
+			err = ErrDescriptorInvalid
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: option %s: %w", ErrDescriptorInvalid, key, err)
+		}
+	}
+	if !seen["profile"] || !seen["net"] || !seen["types"] || !seen["depth"] {
+		return nil, ErrDescriptorInvalid
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func parseAddressTypes(s string) ([]AddressType, error) {
+	var types []AddressType
+	for _, field := range strings.Split(s, "+") {
+		n, err := parsePathUint32(field)
+		if err != nil || n > 0xff {
+			return nil, ErrAddressTypeUnknown
+		}
+		types = append(types, AddressType(n))
+	}
+	if !slices.IsSorted(types) || len(slices.Compact(slices.Clone(types))) != len(types) {
+		return nil, ErrDescriptorInvalid
+	}
+	return types, nil
+}
+
+// DescriptorChecksum returns the BIP-380 checksum of a descriptor body, i.e.
+// of everything before '#', or "" if the body has characters other than
+// printable ASCII.
+func DescriptorChecksum(body string) string {
+	c, class, classCount := uint64(1), 0, 0
+	for i := 0; i < len(body); i++ {
+		position := strings.IndexByte(descriptorInputCharset, body[i])
+		if position < 0 {
+			return ""
+		}
+		c = descriptorPolymod(c, position&31)
+		class = class*3 + position>>5
+		if classCount++; classCount == 3 {
+			c = descriptorPolymod(c, class)
+			class, classCount = 0, 0
+		}
+	}
+	if classCount > 0 {
+		c = descriptorPolymod(c, class)
+	}
+	for i := 0; i < descriptorChecksumSize; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	b := make([]byte, descriptorChecksumSize)
+	for i := range b {
+		b[i] = bech32Charset[(c>>(5*(descriptorChecksumSize-1-i)))&31]
+	}
+	return string(b)
+}
+
+// descriptorPolymod appends the symbol v to the checksum state c, a
+// polynomial over GF(32) modulo the BIP-380 generator.
+func descriptorPolymod(c uint64, v int) uint64 {
+	top := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ uint64(v)
+	for i, g := range [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd} {
+		if (top>>i)&1 == 1 {
+			c ^= g
+		}
+	}
+	return c
+}
@@ -0,0 +1,177 @@
+package aip11_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleParseAccountDescriptor() {
+	d := &aip11.AccountDescriptor{
+		Profile:      aip11.ProfileAIP15,
+		Network:      aip11.Testnet,
+		Context:      []byte("treasury"),
+		AddressTypes: []aip11.AddressType{aip11.AddressTypeFullPrivacy, aip11.AddressTypePseudonym, aip11.AddressTypePseudonymCT},
+		Namespace:    aip11.NamespaceChange,
+		ScanDepth:    aip11.DefaultGapLimit,
+	}
+	s := d.String()
+	fmt.Println(s)
+
+	parsed, _ := aip11.ParseAccountDescriptor(s)
+	fmt.Println(parsed.Profile, parsed.Network, string(parsed.Context), parsed.Namespace, parsed.ScanDepth)
+	// Output:
+	// aip11desc(profile=aip15,net=testnet,ctx=treasury,types=1+2+3,ns=change,depth=20)#gmpd4d4z
+	// aip15 testnet treasury change 20
+}
+
+func TestAccountDescriptor(t *testing.T) {
+	testCases := []struct {
+		name       string
+		descriptor aip11.AccountDescriptor
+	}{
+		{
+			name: "legacy",
+			descriptor: aip11.AccountDescriptor{
+				Profile:      aip11.ProfileLegacy,
+				Network:      aip11.Mainnet,
+				Context:      []byte{},
+				AddressTypes: []aip11.AddressType{aip11.AddressTypeFullPrivacy},
+				ScanDepth:    20,
+			},
+		},
+		{
+			name: "aip11 with binary context",
+			descriptor: aip11.AccountDescriptor{
+				Profile:      aip11.ProfileAIP11,
+				Network:      aip11.Regtest,
+				Context:      aip11.AccountIndexContext(3),
+				AddressTypes: []aip11.AddressType{aip11.AddressTypeFullPrivacy, aip11.AddressTypePseudonym},
+				Namespace:    aip11.NamespaceInternal,
+				ScanDepth:    100,
+			},
+		},
+		{
+			name: "aip15",
+			descriptor: aip11.AccountDescriptor{
+				Profile:      aip11.ProfileAIP15,
+				Network:      aip11.Mainnet,
+				Context:      []byte{},
+				AddressTypes: []aip11.AddressType{aip11.AddressTypePseudonymCT},
+				ScanDepth:    1,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, tc.descriptor.Validate(), "Descriptor should be valid")
+			s := tc.descriptor.String()
+			parsed, err := aip11.ParseAccountDescriptor(s)
+			assert.NoError(t, err, "Descriptor should be parsed correctly")
+			assert.Equal(t, tc.descriptor, *parsed, "Parsed descriptor should be the same")
+			assert.Equal(t, s, parsed.String(), "Descriptor should be serialized canonically")
+		})
+	}
+}
+
+func TestAccountDescriptorMasterSeed(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	d, err := aip11.ParseAccountDescriptor(descriptorWithChecksum("aip11desc(profile=aip11,net=testnet,ctx=0x00ff,types=1,depth=20)"))
+	assert.NoError(t, err, "Descriptor should be parsed correctly")
+
+	masterSeed, err := d.MasterSeed(entropySeed)
+	assert.NoError(t, err, "Master seed should be derived correctly")
+	expected, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{0x00, 0xff}, aip11.Testnet)
+	assert.NoError(t, err, "Master seed should be derived correctly")
+	assert.Equal(t, expected, masterSeed, "Master seed should be the same")
+
+	d.Profile, d.Context = aip11.ProfileLegacy, nil
+	_, err = d.MasterSeed(entropySeed)
+	assert.ErrorIs(t, err, aip11.ErrProfileUnsupported, "Legacy profile should not be derived")
+}
+
+func TestParseAccountDescriptorRejects(t *testing.T) {
+	valid := "aip11desc(profile=aip15,net=mainnet,types=1+3,depth=20)"
+	_, err := aip11.ParseAccountDescriptor(descriptorWithChecksum(valid))
+	assert.NoError(t, err, "Descriptor should be parsed correctly")
+
+	mistyped := strings.Replace(descriptorWithChecksum(valid), "depth=20", "depth=21", 1)
+	_, err = aip11.ParseAccountDescriptor(mistyped)
+	assert.ErrorIs(t, err, aip11.ErrDescriptorChecksum, "Mistyped descriptor should be rejected")
+	_, err = aip11.ParseAccountDescriptor(valid)
+	assert.ErrorIs(t, err, aip11.ErrDescriptorInvalid, "Descriptor without checksum should be rejected")
+
+	for _, body := range []string{
+		"aip11desc(profile=aip11,net=mainnet,types=1+3,depth=20)",          // type 3 needs aip15
+		"aip11desc(profile=aip15,net=mainnet,types=3+1,depth=20)",          // unordered types
+		"aip11desc(profile=aip15,net=mainnet,types=1+1,depth=20)",          // duplicate types
+		"aip11desc(profile=aip15,net=mainnet,types=1,depth=0)",             // zero depth
+		"aip11desc(profile=aip15,net=mainnet,types=1)",                     // missing depth
+		"aip11desc(profile=aip15,net=mainnet,net=testnet,types=1,depth=1)", // repeated option
+		"aip11desc(profile=legacy,net=mainnet,ctx=abc,types=1,depth=1)",    // legacy has no context
+		"aip11desc(profile=legacy,net=mainnet,ns=change,types=1,depth=1)",  // legacy has no namespaces
+		"aip11desc(profile=aip15,net=mainnet,ns=,types=1,depth=1)",         // empty namespace
+		"aip11desc(profile=aip15,net=mainnet,types=1,depth=1,foo=bar)",     // unknown option
+		"aip11desc(profile=aip15,net=mainnet,types=1,depth=1",              // unterminated
+	} {
+		_, err := aip11.ParseAccountDescriptor(descriptorWithChecksum(body))
+		assert.Error(t, err, fmt.Sprintf("Descriptor %s should be rejected", body))
+	}
+
+	for body, expected := range map[string]error{
+		"aip11desc(profile=aip16,net=mainnet,types=1,depth=1)": aip11.ErrProfileUnknown,
+		"aip11desc(profile=aip15,net=moonnet,types=1,depth=1)": aip11.ErrNetworkUnknown,
+		"aip11desc(profile=aip15,net=mainnet,types=4,depth=1)": aip11.ErrAddressTypeUnknown,
+	} {
+		_, err := aip11.ParseAccountDescriptor(descriptorWithChecksum(body))
+		assert.ErrorIs(t, err, expected, fmt.Sprintf("Descriptor %s should be rejected", body))
+	}
+}
+
+func TestDescriptorChecksum(t *testing.T) {
+	// Test vectors of BIP-380.
+	assert.Equal(t, "89f8spxm", aip11.DescriptorChecksum("raw(deadbeef)"), "Checksum should be computed correctly")
+	assert.Equal(t, "", aip11.DescriptorChecksum("raw(d\u00e9adbeef)"), "Non-ASCII body should have no checksum")
+
+	// Every single-character substitution is rejected.
+	valid := descriptorWithChecksum("aip11desc(profile=aip15,net=testnet,ctx=treasury,types=1+2+3,ns=change,depth=20)")
+	for i := 0; i < strings.Index(valid, "#"); i++ {
+		for c := byte(33); c <= 126; c++ {
+			if c == valid[i] || c == '#' {
+				continue
+			}
+			_, err := aip11.ParseAccountDescriptor(valid[:i] + string(c) + valid[i+1:])
+			assert.ErrorIs(t, err, aip11.ErrDescriptorChecksum, "Mistyped character %d should be rejected", i)
+		}
+	}
+}
+
+func TestAccountDescriptorTooLong(t *testing.T) {
+	d := aip11.AccountDescriptor{
+		Profile:      aip11.ProfileAIP11,
+		Network:      aip11.Mainnet,
+		AddressTypes: []aip11.AddressType{aip11.AddressTypeFullPrivacy},
+		Namespace:    aip11.NamespaceReceive,
+		ScanDepth:    aip11.DefaultGapLimit,
+	}
+	base := len(d.String()) - 9
+	d.Context = []byte(strings.Repeat("x", aip11.MaxDescriptorBodyLength-base-len(",ctx=")))
+	assert.NoError(t, d.Validate(), "Descriptor of the maximum length should be valid")
+	assert.Len(t, d.String(), aip11.MaxDescriptorBodyLength+9, "Descriptor should have the maximum length")
+	_, err := aip11.ParseAccountDescriptor(d.String())
+	assert.NoError(t, err, "Descriptor of the maximum length should be parsed correctly")
+
+	d.Context = append(d.Context, 'x')
+	assert.ErrorIs(t, d.Validate(), aip11.ErrDescriptorTooLong, "Overlong descriptor should be rejected")
+	body := d.String()[:aip11.MaxDescriptorBodyLength+1]
+	_, err = aip11.ParseAccountDescriptor(descriptorWithChecksum(body))
+	assert.ErrorIs(t, err, aip11.ErrDescriptorTooLong, "Overlong descriptor should not be parsed")
+}
+
+func descriptorWithChecksum(body string) string {
+	return body + "#" + aip11.DescriptorChecksum(body)
+}
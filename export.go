@@ -0,0 +1,151 @@
+package aip11
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// This file provides authenticated export of public rands from a cold wallet
+// holding the master seed to a hot server handing out addresses.
+//
+// The cold side derives the batch key
+//
+//	batchKey = PRF(masterSeed, "PublicRandBatchKey")
+//
+// and hands it once, separately, to the hot side. The batch key is symmetric:
+// it both creates and verifies tags, so whoever can verify batches can also
+// author them, and it must be kept as secret as the public rands it protects.
+// Every batch is then encoded as
+//
+//	magic "AIP11PRB" || version (1 byte) || network (1 byte) || encode_string(namespace) ||
+//	uint32_be(start) || uint32_be(count) || publicRand_0 || ... || publicRand_{count-1} || tag
+//
+// with tag = KMAC256(batchKey, all preceding bytes, 256, "AIP11PublicRandBatch").
+// The hot side accepts a batch only if the tag verifies, so public rands that
+// were tampered with or substituted in transit by anyone without the batch key
+// are never handed to customers. A compromised hot side, however, can forge
+// batches for itself. The batch key reveals nothing about the master seed or
+// the root seeds.
+
+// Errors
+
+var (
+	ErrPublicRandBatchMalformed     = errors.New("public rand batch is malformed")
+	ErrPublicRandBatchVersion       = errors.New("public rand batch version is not supported")
+	ErrPublicRandBatchAuthFailed    = errors.New("public rand batch authentication failed")
+	ErrPublicRandBatchKeyInvalid    = errors.New("public rand batch key must be exactly 64 bytes")
+	ErrPublicRandBatchCountExceeded = errors.New("public rand batch is too large")
+)
+
+const (
+	publicRandBatchMagic         = "AIP11PRB"
+	publicRandBatchVersion       = 0x01
+	publicRandBatchKeyLabel      = "PublicRandBatchKey"
+	publicRandBatchCustomization = "AIP11PublicRandBatch"
+	publicRandBatchTagSize       = 32
+
+	// MaxPublicRandBatchCount is the maximum number of public rands in a batch.
+	MaxPublicRandBatchCount = 1 << 20
+)
+
+// PublicRandBatch is a verified batch of public rands with sequence numbers
+// Start to Start+len(PublicRands)-1.
+type PublicRandBatch struct {
+	Network     Network
+	Namespace   PublicRandNamespace
+	Start       uint32
+	PublicRands [][]byte
+}
+
+// PublicRandBatchKey derives the symmetric key that authenticates batches
+// exported from the master seed. The same key is needed to verify batches and
+// suffices to create them.
+func PublicRandBatchKey(masterSeed []byte) ([]byte, error) {
+	return defaultDeriver.PublicRandBatchKey(SoftwareKey(masterSeed))
+}
+
+// ExportPublicRandBatch derives the public rands of the namespace with
+// sequence numbers start to start+count-1 from the master seed and encodes
+// them as an authenticated batch for the given network.
+func ExportPublicRandBatch(ctx context.Context, masterSeed []byte, network Network, ns PublicRandNamespace, start, count uint32) ([]byte, error) {
+	if !network.Valid() {
+		return nil, ErrNetworkUnknown
+	}
+	if count > MaxPublicRandBatchCount {
+		return nil, ErrPublicRandBatchCountExceeded
+	}
+	key, err := PublicRandBatchKey(masterSeed)
+	if err != nil {
+		return nil, err
+	}
+	root, err := MasterSeedToNamespacePublicRandRootSeed(masterSeed, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte(publicRandBatchMagic)
+	data = append(data, publicRandBatchVersion, byte(network))
+	data = append(data, encodeString([]byte(ns))...)
+	data = binary.BigEndian.AppendUint32(data, start)
+	data = binary.BigEndian.AppendUint32(data, count)
+	body := len(data)
+	data = append(data, make([]byte, int(count)*PublicRandSize)...)
+	if err := DerivePublicRandRange(ctx, root, start, count, data[body:]); err != nil {
+		return nil, err
+	}
+	return append(data, publicRandBatchTag(key, data)...), nil
+}
+
+// VerifyPublicRandBatch authenticates a batch produced by ExportPublicRandBatch
+// with the batch key, checks its network and decodes it.
+func VerifyPublicRandBatch(data []byte, batchKey []byte, network Network) (*PublicRandBatch, error) {
+	if len(batchKey) != 64 {
+		return nil, ErrPublicRandBatchKeyInvalid
+	}
+	if len(data) < len(publicRandBatchMagic)+2+publicRandBatchTagSize ||
+		string(data[:len(publicRandBatchMagic)]) != publicRandBatchMagic {
+		return nil, ErrPublicRandBatchMalformed
+	}
+	if data[len(publicRandBatchMagic)] != publicRandBatchVersion {
+		return nil, ErrPublicRandBatchVersion
+	}
+
+	message, tag := data[:len(data)-publicRandBatchTagSize], data[len(data)-publicRandBatchTagSize:]
+	if subtle.ConstantTimeCompare(tag, publicRandBatchTag(batchKey, message)) != 1 {
+		return nil, ErrPublicRandBatchAuthFailed
+	}
+
+	rest := message[len(publicRandBatchMagic)+1:]
+	batch := &PublicRandBatch{Network: Network(rest[0])}
+	if err := CheckNetwork(network, batch.Network); err != nil {
+		return nil, err
+	}
+	ns, rest, err := decodeString(rest[1:])
+	if err != nil || len(rest) < 8 {
+		return nil, ErrPublicRandBatchMalformed
+	}
+	batch.Namespace = PublicRandNamespace(ns)
+	batch.Start = binary.BigEndian.Uint32(rest)
+	count := binary.BigEndian.Uint32(rest[4:])
+	rest = rest[8:]
+	if !batch.Namespace.Valid() || count > MaxPublicRandBatchCount || uint64(len(rest)) != uint64(count)*PublicRandSize ||
+		(count > 0 && uint64(batch.Start)+uint64(count)-1 > uint64(MaxSeqNo)) {
+		return nil, ErrPublicRandBatchMalformed
+	}
+
+	batch.PublicRands = make([][]byte, count)
+	for i := range batch.PublicRands {
+		publicRand := make([]byte, PublicRandSize)
+		copy(publicRand, rest[i*PublicRandSize:])
+		batch.PublicRands[i] = publicRand
+	}
+	return batch, nil
+}
+
+func publicRandBatchTag(key, message []byte) []byte {
+	kmac256 := NewKMAC256(key, publicRandBatchTagSize, []byte(publicRandBatchCustomization))
+	kmac256.Write(message)
+	return kmac256.Sum(nil)
+}
@@ -0,0 +1,93 @@
+package aip11_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleExportPublicRandBatch() {
+	// Cold side.
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	batchKey, _ := aip11.PublicRandBatchKey(masterSeed)
+	data, _ := aip11.ExportPublicRandBatch(context.Background(), masterSeed, aip11.Mainnet, aip11.NamespaceReceive, 0, 100)
+
+	// Hot side.
+	batch, err := aip11.VerifyPublicRandBatch(data, batchKey, aip11.Mainnet)
+	fmt.Println(err, batch.Start, len(batch.PublicRands))
+	// Output: <nil> 0 100
+}
+
+func TestExportPublicRandBatch(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	masterSeed, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Testnet)
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	batchKey, err := aip11.PublicRandBatchKey(masterSeed)
+	assert.NoError(t, err, "Batch key should be derived correctly")
+
+	for _, ns := range []aip11.PublicRandNamespace{aip11.NamespaceReceive, aip11.NamespaceChange} {
+		t.Run(fmt.Sprintf("namespace %q", ns), func(t *testing.T) {
+			data, err := aip11.ExportPublicRandBatch(context.Background(), masterSeed, aip11.Testnet, ns, 50, 20)
+			assert.NoError(t, err, "Batch should be exported correctly")
+			batch, err := aip11.VerifyPublicRandBatch(data, batchKey, aip11.Testnet)
+			assert.NoError(t, err, "Batch should be verified correctly")
+			assert.Equal(t, aip11.Testnet, batch.Network, "Network should be the same")
+			assert.Equal(t, ns, batch.Namespace, "Namespace should be the same")
+			assert.Equal(t, uint32(50), batch.Start, "Start should be the same")
+			assert.Equal(t, 20, len(batch.PublicRands), "Count should be the same")
+
+			root, err := aip11.MasterSeedToNamespacePublicRandRootSeed(masterSeed, ns)
+			assert.NoError(t, err, "Namespace root seed should be derived correctly")
+			for i, publicRand := range batch.PublicRands {
+				expected, err := aip11.DerivePublicRand(root, 50+uint32(i))
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				assert.Equal(t, expected, publicRand, "Public rand should be the same")
+			}
+		})
+	}
+}
+
+func TestVerifyPublicRandBatchRejects(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	masterSeed, err := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	batchKey, err := aip11.PublicRandBatchKey(masterSeed)
+	assert.NoError(t, err, "Batch key should be derived correctly")
+	data, err := aip11.ExportPublicRandBatch(context.Background(), masterSeed, aip11.Mainnet, aip11.NamespaceReceive, 0, 5)
+	assert.NoError(t, err, "Batch should be exported correctly")
+
+	otherMasterSeed, err := aip11.AccountN(entropySeed, 1)
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	otherKey, err := aip11.PublicRandBatchKey(otherMasterSeed)
+	assert.NoError(t, err, "Batch key should be derived correctly")
+	substituted, err := aip11.ExportPublicRandBatch(context.Background(), otherMasterSeed, aip11.Mainnet, aip11.NamespaceReceive, 0, 5)
+	assert.NoError(t, err, "Batch should be exported correctly")
+
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-100] ^= 1
+
+	_, err = aip11.VerifyPublicRandBatch(tampered, batchKey, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandBatchAuthFailed, "Tampered public rand should be rejected")
+	_, err = aip11.VerifyPublicRandBatch(substituted, batchKey, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandBatchAuthFailed, "Batch of another account should be rejected")
+	_, err = aip11.VerifyPublicRandBatch(data, otherKey, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandBatchAuthFailed, "Wrong batch key should be rejected")
+	_, err = aip11.VerifyPublicRandBatch(data, batchKey, aip11.Testnet)
+	assert.ErrorIs(t, err, aip11.ErrNetworkMismatch, "Batch of another network should be rejected")
+	_, err = aip11.VerifyPublicRandBatch(data[:10], batchKey, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandBatchMalformed, "Truncated batch should be rejected")
+	_, err = aip11.VerifyPublicRandBatch(data, batchKey[:32], aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandBatchKeyInvalid, "Short batch key should be rejected")
+
+	_, err = aip11.ExportPublicRandBatch(context.Background(), masterSeed, aip11.Network(7), aip11.NamespaceReceive, 0, 5)
+	assert.ErrorIs(t, err, aip11.ErrNetworkUnknown, "Unknown network should be rejected")
+	_, err = aip11.ExportPublicRandBatch(context.Background(), masterSeed, aip11.Mainnet, aip11.NamespaceReceive, 0, aip11.MaxPublicRandBatchCount+1)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandBatchCountExceeded, "Oversized batch should be rejected")
+}
@@ -0,0 +1,136 @@
+package aip11
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// This file provides a non-secret fingerprint identifying a wallet:
+//
+//	fingerprint = PRF(masterSeed, "WalletFingerprint")[:8]
+//
+// The fingerprint is one-way and is not an input of any other derivation, so
+// it can be shown to support staff or printed on a backup card. It can be
+// rendered as hex, as words of a wordlist, or as randomart, a visual hash
+// drawn with the drunken bishop algorithm of OpenSSH.
+
+// Errors
+
+var (
+	ErrFingerprintInvalid = errors.New("fingerprint must be exactly 16 hex characters")
+)
+
+const (
+	fingerprintLabel = "WalletFingerprint"
+
+	// FingerprintSize is the size of a fingerprint in bytes.
+	FingerprintSize = 8
+	// FingerprintWords is the number of words of Fingerprint.Words.
+	FingerprintWords = 5
+)
+
+// Fingerprint identifies a wallet without revealing its seeds.
+type Fingerprint [FingerprintSize]byte
+
+// MasterSeedToFingerprint derives the fingerprint of the master seed.
+func MasterSeedToFingerprint(masterSeed []byte) (Fingerprint, error) {
+	return defaultDeriver.MasterSeedToFingerprint(SoftwareKey(masterSeed))
+}
+
+// ParseFingerprint parses the hex form of a fingerprint, in either case.
+func ParseFingerprint(s string) (Fingerprint, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != FingerprintSize {
+		return Fingerprint{}, ErrFingerprintInvalid
+	}
+	var f Fingerprint
+	copy(f[:], b)
+	return f, nil
+}
+
+// Hex returns the fingerprint as lower-case hex.
+func (f Fingerprint) Hex() string {
+	return hex.EncodeToString(f[:])
+}
+
+// String returns the fingerprint as lower-case hex.
+func (f Fingerprint) String() string {
+	return f.Hex()
+}
+
+// Words returns the first 55 bits of the fingerprint as FingerprintWords words
+// of the wordlist, 11 bits per word.
+func (f Fingerprint) Words(wordlist []string) ([]string, error) {
+	if len(wordlist) != 2048 {
+		return nil, ErrWordlistLengthInvalid
+	}
+	bits := BytesToBits(f[:])
+	words := make([]string, FingerprintWords)
+	for i := range words {
+		index := 0
+		for _, bit := range bits[i*11 : (i+1)*11] {
+			index <<= 1
+			if bit {
+				index |= 1
+			}
+		}
+		words[i] = wordlist[index]
+	}
+	return words, nil
+}
+
+// Randomart returns the fingerprint drawn by the drunken bishop algorithm on a
+// 17x9 board, with a frame titled "AIP11", as lines separated by '\n'.
+func (f Fingerprint) Randomart() string {
+	const (
+		width   = 17
+		height  = 9
+		symbols = " .o+=*BOX@%&#/^"
+	)
+	var board [height][width]int
+	x, y := width/2, height/2
+	for _, b := range f {
+		// Each byte gives four moves, least significant bits first. Bit 0
+		// moves right (1) or left (0), bit 1 moves down (1) or up (0).
+		for i := 0; i < 4; i++ {
+			if b&1 != 0 {
+				x = min(x+1, width-1)
+			} else {
+				x = max(x-1, 0)
+			}
+			if b&2 != 0 {
+				y = min(y+1, height-1)
+			} else {
+				y = max(y-1, 0)
+			}
+			board[y][x]++
+			b >>= 2
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("+" + centered("[AIP11]", width) + "+\n")
+	for row := range board {
+		sb.WriteByte('|')
+		for col, visits := range board[row] {
+			switch {
+			case row == height/2 && col == width/2:
+				sb.WriteByte('S')
+			case row == y && col == x:
+				sb.WriteByte('E')
+			default:
+				sb.WriteByte(symbols[min(visits, len(symbols)-1)])
+			}
+		}
+		sb.WriteString("|\n")
+	}
+	sb.WriteString("+" + strings.Repeat("-", width) + "+")
+	return sb.String()
+}
+
+// centered pads the title with '-' to the given width.
+func centered(title string, width int) string {
+	left := (width - len(title)) / 2
+	return strings.Repeat("-", left) + title + strings.Repeat("-", width-len(title)-left)
+}
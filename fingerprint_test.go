@@ -0,0 +1,81 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/pqabelian/abelian-aip11-go/wordlists"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleMasterSeedToFingerprint() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+
+	fingerprint, _ := aip11.MasterSeedToFingerprint(masterSeed)
+	words, _ := fingerprint.Words(wordlists.English)
+	fmt.Println(len(fingerprint.Hex()), len(words))
+	// Output: 16 5
+}
+
+func TestMasterSeedToFingerprint(t *testing.T) {
+	for i, v := range getAIP11Vector() {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			masterSeed, err := hex.DecodeString(v.masterSeed)
+			assert.NoError(t, err, "Master seed should be decoded correctly")
+
+			fingerprint, err := aip11.MasterSeedToFingerprint(masterSeed)
+			assert.NoError(t, err, "Fingerprint should be derived correctly")
+			expected := aip11.PRF(masterSeed, []byte("WalletFingerprint"))[:aip11.FingerprintSize]
+			assert.Equal(t, hex.EncodeToString(expected), fingerprint.Hex(), "Fingerprint should be derived correctly")
+
+			parsed, err := aip11.ParseFingerprint(strings.ToUpper(fingerprint.Hex()))
+			assert.NoError(t, err, "Fingerprint should be parsed correctly")
+			assert.Equal(t, fingerprint, parsed, "Parsed fingerprint should be the same")
+
+			words, err := fingerprint.Words(wordlists.English)
+			assert.NoError(t, err, "Fingerprint words should be generated correctly")
+			mnemonic, err := aip11.EntropySeedToMnemonic(append(fingerprint[:], make([]byte, 24)...), wordlists.English)
+			assert.NoError(t, err, "Mnemonic should be generated correctly")
+			assert.Equal(t, mnemonic[:aip11.FingerprintWords], words, "Fingerprint words should be the leading bits in words")
+
+			art := fingerprint.Randomart()
+			lines := strings.Split(art, "\n")
+			assert.Equal(t, 11, len(lines), "Randomart should have a framed 9-row board")
+			for _, line := range lines {
+				assert.Equal(t, 19, len(line), "Randomart should have a framed 17-column board")
+			}
+			assert.Equal(t, "+-----[AIP11]-----+", lines[0], "Randomart should be titled")
+			assert.Equal(t, 1, strings.Count(art, "S"), "Randomart should mark the start")
+			assert.Equal(t, art, fingerprint.Randomart(), "Randomart should be deterministic")
+		})
+	}
+}
+
+func TestFingerprintDistinguishesWallets(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	seen := map[aip11.Fingerprint]bool{}
+	for n := uint32(0); n < 8; n++ {
+		masterSeed, err := aip11.AccountN(entropySeed, n)
+		assert.NoError(t, err, "Master seed should be generated correctly")
+		fingerprint, err := aip11.MasterSeedToFingerprint(masterSeed)
+		assert.NoError(t, err, "Fingerprint should be derived correctly")
+		assert.False(t, seen[fingerprint], "Fingerprints of different accounts should differ")
+		seen[fingerprint] = true
+	}
+}
+
+func TestFingerprintRejects(t *testing.T) {
+	_, err := aip11.MasterSeedToFingerprint(make([]byte, 32))
+	assert.ErrorIs(t, err, aip11.ErrMasterSeedInvalid, "Invalid master seed should be rejected")
+	_, err = aip11.ParseFingerprint("0011")
+	assert.ErrorIs(t, err, aip11.ErrFingerprintInvalid, "Short fingerprint should be rejected")
+	_, err = aip11.ParseFingerprint("zz11223344556677")
+	assert.ErrorIs(t, err, aip11.ErrFingerprintInvalid, "Non-hex fingerprint should be rejected")
+	_, err = aip11.Fingerprint{}.Words(wordlists.English[:10])
+	assert.ErrorIs(t, err, aip11.ErrWordlistLengthInvalid, "Invalid wordlist should be rejected")
+}
@@ -0,0 +1,50 @@
+package aip11
+
+import (
+	"iter"
+)
+
+// PublicRands returns an iterator over the count public rands with sequence
+// numbers start, start+1, ..., start+count-1, yielding each sequence number
+// with its public rand:
+//
+//	publicRands, err := aip11.PublicRands(publicRandRootSeed, 0, n)
+//	if err != nil {
+//		...
+//	}
+//	for seqNo, publicRand := range publicRands {
+//		...
+//	}
+//
+// The keyed KMAC state is built once and reused for every sequence number.
+// Each yielded public rand is a fresh slice owned by the caller. The iteration
+// stops at MaxSeqNo.
+func PublicRands(publicRandRootSeed []byte, start, count uint32) (iter.Seq2[uint32, []byte], error) {
+	d, err := newPublicRandDeriver(publicRandRootSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return func(yield func(uint32, []byte) bool) {}, nil
+	}
+	return d.through(start, uint32(min(uint64(start)+uint64(count)-1, uint64(MaxSeqNo)))), nil
+}
+
+// through returns an iterator over the public rands with sequence numbers
+// first to last inclusive, so that it can reach MaxSeqNo, which no count from
+// 0 can.
+func (d *publicRandDeriver) through(first, last uint32) iter.Seq2[uint32, []byte] {
+	return func(yield func(uint32, []byte) bool) {
+		if first > last {
+			return
+		}
+		for seqNo := first; ; seqNo++ {
+			publicRand := make([]byte, PublicRandSize)
+			d.derive(seqNo, publicRand)
+			if !yield(seqNo, publicRand) || seqNo == last {
+				return
+			}
+		}
+	}
+}
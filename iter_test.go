@@ -0,0 +1,88 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"iter"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExamplePublicRands() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	publicRandRootSeed, _ := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+
+	publicRands, _ := aip11.PublicRands(publicRandRootSeed, 1, 3)
+	for seqNo, publicRand := range publicRands {
+		fmt.Println(seqNo, len(publicRand))
+	}
+	// Output: 1 64
+	// 2 64
+	// 3 64
+}
+
+func TestPublicRands(t *testing.T) {
+	vectors := getAIP11Vector()
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			publicRandRootSeed, err := hex.DecodeString(v.publicRandRootSeed)
+			assert.NoError(t, err, "Public rand root seed should be decoded correctly")
+			for _, publicRand := range v.publicRands {
+				publicRands, err := aip11.PublicRands(publicRandRootSeed, publicRand.seqNo, 1)
+				assert.NoError(t, err, "Public rands should be generated correctly")
+				for seqNo, result := range publicRands {
+					assert.Equal(t, publicRand.seqNo, seqNo, "Sequence number should be the same")
+					assert.Equal(t, publicRand.expected, hex.EncodeToString(result), "Public rand should be the same")
+				}
+			}
+		})
+	}
+}
+
+func TestPublicRandsRange(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+
+	publicRands := func(start, count uint32) iter.Seq2[uint32, []byte] {
+		publicRands, err := aip11.PublicRands(publicRandRootSeed, start, count)
+		assert.NoError(t, err, "Public rands should be generated correctly")
+		return publicRands
+	}
+
+	seqNos := []uint32{}
+	for seqNo, publicRand := range publicRands(100, 50) {
+		expected, err := aip11.DerivePublicRand(publicRandRootSeed, seqNo)
+		assert.NoError(t, err, "Public rand should be generated correctly")
+		assert.Equal(t, expected, publicRand, "Public rand should be the same")
+		seqNos = append(seqNos, seqNo)
+	}
+	assert.Equal(t, 50, len(seqNos), "All public rands should be yielded")
+	assert.Equal(t, uint32(100), seqNos[0], "First sequence number should be the start")
+	assert.Equal(t, uint32(149), seqNos[49], "Last sequence number should be start+count-1")
+
+	seqNos = seqNos[:0]
+	for seqNo := range publicRands(0, 1000) {
+		if seqNo == 3 {
+			break
+		}
+		seqNos = append(seqNos, seqNo)
+	}
+	assert.Equal(t, []uint32{0, 1, 2}, seqNos, "Iteration should stop early")
+
+	seqNos = seqNos[:0]
+	for seqNo := range publicRands(aip11.MaxSeqNo-1, 10) {
+		seqNos = append(seqNos, seqNo)
+	}
+	assert.Equal(t, []uint32{aip11.MaxSeqNo - 1, aip11.MaxSeqNo}, seqNos, "Iteration should stop at MaxSeqNo")
+
+	count := 0
+	for range publicRands(0, 0) {
+		count++
+	}
+	assert.Equal(t, 0, count, "Empty sequence should yield nothing")
+
+	_, err := aip11.PublicRands(publicRandRootSeed[:32], 0, 10)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandRootSeedInvalid, "Invalid public rand root seed should be rejected")
+}
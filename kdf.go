@@ -0,0 +1,189 @@
+package aip11
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// This file provides the memory-hard password stretching used for passphrases
+// and keystores. KDFParams records every parameter needed to reproduce a
+// derivation and has a canonical string form, e.g.
+//
+//	argon2id:t=3,m=65536,p=4
+//	scrypt:logn=17,r=8,p=1
+//	none
+
+// Errors
+
+var (
+	ErrKDFAlgorithmUnknown = errors.New("unknown key derivation function")
+	ErrKDFParamsInvalid    = errors.New("key derivation function parameters are invalid")
+)
+
+// KDFAlgorithm identifies a password-based key derivation function.
+type KDFAlgorithm byte
+
+const (
+	KDFNone     KDFAlgorithm = 0x00
+	KDFArgon2id KDFAlgorithm = 0x01
+	KDFScrypt   KDFAlgorithm = 0x02
+)
+
+// String returns the name of the algorithm.
+func (a KDFAlgorithm) String() string {
+	switch a {
+	case KDFNone:
+		return "none"
+	case KDFArgon2id:
+		return "argon2id"
+	case KDFScrypt:
+		return "scrypt"
+	default:
+		return fmt.Sprintf("kdf(%d)", byte(a))
+	}
+}
+
+// KDFParams are the parameters of a password-based key derivation function.
+type KDFParams struct {
+	Algorithm KDFAlgorithm
+
+	// Argon2id parameters: number of passes, memory in KiB and parallelism.
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+
+	// scrypt parameters: log2 of the cost N, block size r and parallelism p.
+	LogN uint8
+	R    uint32
+	P    uint32
+}
+
+// DefaultArgon2idParams returns the recommended Argon2id parameters of
+// RFC 9106, section 4, second recommended option.
+func DefaultArgon2idParams() KDFParams {
+	return KDFParams{Algorithm: KDFArgon2id, Time: 3, Memory: 64 * 1024, Threads: 4}
+}
+
+// DefaultScryptParams returns the recommended scrypt parameters for
+// interactive use.
+func DefaultScryptParams() KDFParams {
+	return KDFParams{Algorithm: KDFScrypt, LogN: 17, R: 8, P: 1}
+}
+
+// Validate checks that the parameters are usable.
+func (p KDFParams) Validate() error {
+	switch p.Algorithm {
+	case KDFNone:
+		if p != (KDFParams{}) {
+			return ErrKDFParamsInvalid
+		}
+	case KDFArgon2id:
+		if p.Time < 1 || p.Threads < 1 || p.Memory < 8*uint32(p.Threads) || p.LogN != 0 || p.R != 0 || p.P != 0 {
+			return ErrKDFParamsInvalid
+		}
+	case KDFScrypt:
+		if p.LogN < 1 || p.LogN > 62 || p.R < 1 || p.P < 1 || uint64(p.R)*uint64(p.P) >= 1<<30 ||
+			p.Time != 0 || p.Memory != 0 || p.Threads != 0 {
+			return ErrKDFParamsInvalid
+		}
+	default:
+		return ErrKDFAlgorithmUnknown
+	}
+	return nil
+}
+
+// Derive stretches the password with the given salt into a key of keyLen
+// bytes. With KDFNone it returns ErrKDFParamsInvalid.
+func (p KDFParams) Derive(password, salt []byte, keyLen int) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	switch p.Algorithm {
+	case KDFArgon2id:
+		return argon2.IDKey(password, salt, p.Time, p.Memory, p.Threads, uint32(keyLen)), nil
+	case KDFScrypt:
+		return scrypt.Key(password, salt, 1<<p.LogN, int(p.R), int(p.P), keyLen)
+	default:
+		return nil, ErrKDFParamsInvalid
+	}
+}
+
+// String returns the canonical string form of the parameters.
+func (p KDFParams) String() string {
+	switch p.Algorithm {
+	case KDFArgon2id:
+		return fmt.Sprintf("argon2id:t=%d,m=%d,p=%d", p.Time, p.Memory, p.Threads)
+	case KDFScrypt:
+		return fmt.Sprintf("scrypt:logn=%d,r=%d,p=%d", p.LogN, p.R, p.P)
+	default:
+		return p.Algorithm.String()
+	}
+}
+
+// ParseKDFParams parses the string form returned by KDFParams.String. Any
+// other form, even of valid parameters, is rejected.
+func ParseKDFParams(s string) (KDFParams, error) {
+	name, args, _ := strings.Cut(s, ":")
+
+	var p KDFParams
+	var keys []string
+	switch name {
+	case "none":
+		if s != "none" {
+			return KDFParams{}, ErrKDFParamsInvalid
+		}
+		return p, nil
+	case "argon2id":
+		p.Algorithm = KDFArgon2id
+		keys = []string{"t", "m", "p"}
+	case "scrypt":
+		p.Algorithm = KDFScrypt
+		keys = []string{"logn", "r", "p"}
+	default:
+		return KDFParams{}, ErrKDFAlgorithmUnknown
+	}
+
+	fields := strings.Split(args, ",")
+	if len(fields) != len(keys) {
+		return KDFParams{}, ErrKDFParamsInvalid
+	}
+	values := make([]uint64, len(keys))
+	for i, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key != keys[i] {
+			return KDFParams{}, ErrKDFParamsInvalid
+		}
+		v, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return KDFParams{}, ErrKDFParamsInvalid
+		}
+		values[i] = v
+	}
+
+	switch p.Algorithm {
+	case KDFArgon2id:
+		if values[2] > 255 {
+			return KDFParams{}, ErrKDFParamsInvalid
+		}
+		p.Time, p.Memory, p.Threads = uint32(values[0]), uint32(values[1]), uint8(values[2])
+	case KDFScrypt:
+		if values[0] > 255 {
+			return KDFParams{}, ErrKDFParamsInvalid
+		}
+		p.LogN, p.R, p.P = uint8(values[0]), uint32(values[1]), uint32(values[2])
+	}
+	if err := p.Validate(); err != nil {
+		return KDFParams{}, err
+	}
+	// Reject leading zeros and signs, so that every parameter set has exactly
+	// one string form.
+	if p.String() != s {
+		return KDFParams{}, ErrKDFParamsInvalid
+	}
+	return p, nil
+}
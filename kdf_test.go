@@ -0,0 +1,75 @@
+package aip11_test
+
+import (
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKDFParamsString(t *testing.T) {
+	testCases := []struct {
+		params   aip11.KDFParams
+		expected string
+	}{
+		{params: aip11.KDFParams{}, expected: "none"},
+		{params: aip11.DefaultArgon2idParams(), expected: "argon2id:t=3,m=65536,p=4"},
+		{params: aip11.DefaultScryptParams(), expected: "scrypt:logn=17,r=8,p=1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.params.String(), "KDF params should be formatted correctly")
+			params, err := aip11.ParseKDFParams(tc.expected)
+			assert.NoError(t, err, "KDF params should be parsed correctly")
+			assert.Equal(t, tc.params, params, "Parsed KDF params should be the same")
+		})
+	}
+}
+
+func TestParseKDFParamsInvalid(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected error
+	}{
+		{input: "pbkdf2:c=2048", expected: aip11.ErrKDFAlgorithmUnknown},
+		{input: "none:t=1", expected: aip11.ErrKDFParamsInvalid},
+		{input: "argon2id:t=3,m=65536", expected: aip11.ErrKDFParamsInvalid},
+		{input: "argon2id:m=65536,t=3,p=4", expected: aip11.ErrKDFParamsInvalid},
+		{input: "argon2id:t=0,m=65536,p=4", expected: aip11.ErrKDFParamsInvalid},
+		{input: "argon2id:t=3,m=65536,p=256", expected: aip11.ErrKDFParamsInvalid},
+		{input: "scrypt:logn=0,r=8,p=1", expected: aip11.ErrKDFParamsInvalid},
+		{input: "scrypt:logn=17,r=x,p=1", expected: aip11.ErrKDFParamsInvalid},
+		{input: "none:", expected: aip11.ErrKDFParamsInvalid},
+		{input: "argon2id:t=03,m=65536,p=4", expected: aip11.ErrKDFParamsInvalid},
+		{input: "scrypt:logn=+17,r=8,p=1", expected: aip11.ErrKDFParamsInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := aip11.ParseKDFParams(tc.input)
+			assert.ErrorIs(t, err, tc.expected, "Invalid KDF params should be rejected")
+		})
+	}
+}
+
+func TestKDFParamsDerive(t *testing.T) {
+	testCases := []aip11.KDFParams{
+		{Algorithm: aip11.KDFArgon2id, Time: 1, Memory: 64, Threads: 1},
+		{Algorithm: aip11.KDFScrypt, LogN: 4, R: 8, P: 1},
+	}
+	for _, params := range testCases {
+		t.Run(params.String(), func(t *testing.T) {
+			key1, err := params.Derive([]byte("password"), []byte("salt-salt-salt-salt"), 32)
+			assert.NoError(t, err, "Key should be derived correctly")
+			key2, err := params.Derive([]byte("password"), []byte("salt-salt-salt-salt"), 32)
+			assert.NoError(t, err, "Key should be derived correctly")
+			key3, err := params.Derive([]byte("Password"), []byte("salt-salt-salt-salt"), 32)
+			assert.NoError(t, err, "Key should be derived correctly")
+			assert.Equal(t, 32, len(key1), "Key length should be 32")
+			assert.Equal(t, key1, key2, "Key derivation should be deterministic")
+			assert.NotEqual(t, key1, key3, "Different passwords should give different keys")
+		})
+	}
+
+	_, err := aip11.KDFParams{}.Derive([]byte("password"), []byte("salt"), 32)
+	assert.ErrorIs(t, err, aip11.ErrKDFParamsInvalid, "KDFNone should not derive keys")
+}
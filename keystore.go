@@ -0,0 +1,286 @@
+package aip11
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/sha3"
+)
+
+// This file provides a password-encrypted keystore for entropy seeds. A
+// keystore is encoded as
+//
+//	magic "AIP11KST" || version (1 byte) || encode_string(KDFParams.String()) ||
+//	salt (32 bytes) || nonce (24 bytes) || profile (1 byte) || network (1 byte) ||
+//	encode_string(label) || encode_string(customizationContext) ||
+//	uint64_be(creation time) || uint64_be(creation height) ||
+//	ciphertext (48 bytes) || checksum (first 4 bytes of SHA3-256 of all preceding bytes)
+//
+// The ciphertext is the XChaCha20-Poly1305 encryption of the entropy seed
+// under KDF(NFKD(password), salt), with everything before it as associated
+// data, so the metadata cannot be altered without the password either. The
+// creation time is in seconds since the Unix epoch, and 0 if unknown.
+//
+// The checksum tells accidental corruption of the file, reported as
+// ErrKeystoreCorrupted, from a wrong password, reported as ErrKeystorePassword.
+// A deliberate modification with a recomputed checksum is also reported as
+// ErrKeystorePassword, as the two cannot be told apart without the password.
+//
+// The KDF string must be in the canonical form of KDFParams.String, as it is
+// authenticated as re-encoded, and must name a password hash within the cost
+// limits below: a keystore file is untrusted input, and its KDF parameters are
+// evaluated before the password can be checked.
+
+// Errors
+
+var (
+	ErrKeystoreMalformed       = errors.New("keystore is malformed")
+	ErrKeystoreVersion         = errors.New("keystore version is not supported")
+	ErrKeystoreCorrupted       = errors.New("keystore checksum does not match, the keystore is corrupted")
+	ErrKeystorePassword        = errors.New("keystore password is incorrect")
+	ErrKeystoreMetadataInvalid = errors.New("keystore metadata is invalid")
+	ErrKeystoreKDFCostExceeded = errors.New("keystore KDF parameters exceed the cost limits")
+)
+
+const (
+	keystoreMagic        = "AIP11KST"
+	keystoreVersion      = 0x01
+	keystoreSaltSize     = 32
+	keystoreChecksumSize = 4
+	keystoreSeedSize     = 32
+
+	// MaxKeystoreKDFMemory is the maximum memory of the keystore KDF in bytes:
+	// the Argon2id memory, or 128 * r * 2^logN for scrypt.
+	MaxKeystoreKDFMemory = 4 << 30
+	// MaxKeystoreArgon2idTime is the maximum number of Argon2id passes.
+	MaxKeystoreArgon2idTime = 16
+	// MaxKeystoreScryptP is the maximum scrypt parallelism.
+	MaxKeystoreScryptP = 16
+)
+
+// KeystoreMetadata describes the account of a keystore. It is stored in the
+// clear, but authenticated by the password.
+type KeystoreMetadata struct {
+	Label                string
+	Profile              Profile
+	Network              Network
+	CustomizationContext []byte
+	CreationTime         time.Time
+	CreationHeight       uint64
+}
+
+// Validate checks that the metadata can be stored. As in account descriptors,
+// the legacy profile has no customizationContext.
+func (m *KeystoreMetadata) Validate() error {
+	if !m.Profile.Valid() {
+		return ErrProfileUnknown
+	}
+	if !m.Network.Valid() {
+		return ErrNetworkUnknown
+	}
+	if m.Profile == ProfileLegacy && len(m.CustomizationContext) != 0 {
+		return ErrKeystoreMetadataInvalid
+	}
+	if !m.CreationTime.IsZero() && m.CreationTime.Unix() <= 0 {
+		return ErrKeystoreMetadataInvalid
+	}
+	return nil
+}
+
+// Keystore is an entropy seed encrypted under a password. Metadata and KDF
+// are authenticated, so modifying them makes Decrypt fail.
+type Keystore struct {
+	Metadata KeystoreMetadata
+	KDF      KDFParams
+
+	salt       []byte
+	nonce      []byte
+	ciphertext []byte
+}
+
+// NewKeystore encrypts the entropy seed under the password, stretched with the
+// KDF parameters, e.g. DefaultArgon2idParams().
+func NewKeystore(entropySeed []byte, password string, params KDFParams, metadata KeystoreMetadata) (*Keystore, error) {
+	if len(entropySeed) != 32 {
+		return nil, ErrEntropySeedInvalid
+	}
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+
+	k := &Keystore{Metadata: metadata, KDF: params}
+	k.Metadata.CustomizationContext = bytes.Clone(metadata.CustomizationContext)
+	if err := k.encrypt(entropySeed, password); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Decrypt returns the entropy seed, or ErrKeystorePassword if the password is
+// wrong.
+func (k *Keystore) Decrypt(password string) ([]byte, error) {
+	aead, err := k.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	entropySeed, err := aead.Open(nil, k.nonce, k.ciphertext, k.header())
+	if err != nil {
+		return nil, ErrKeystorePassword
+	}
+	return entropySeed, nil
+}
+
+// ChangePassword re-encrypts the entropy seed under the new password and KDF
+// parameters, with a fresh salt and nonce. On error the keystore is unchanged.
+func (k *Keystore) ChangePassword(oldPassword, newPassword string, params KDFParams) error {
+	entropySeed, err := k.Decrypt(oldPassword)
+	if err != nil {
+		return err
+	}
+	defer clear(entropySeed)
+
+	changed := &Keystore{Metadata: k.Metadata, KDF: params}
+	if err := changed.encrypt(entropySeed, newPassword); err != nil {
+		return err
+	}
+	*k = *changed
+	return nil
+}
+
+// MarshalBinary encodes the keystore.
+func (k *Keystore) MarshalBinary() ([]byte, error) {
+	if len(k.ciphertext) != keystoreSeedSize+chacha20poly1305.Overhead {
+		return nil, ErrKeystoreMalformed
+	}
+	data := append(k.header(), k.ciphertext...)
+	return append(data, keystoreChecksum(data)...), nil
+}
+
+// UnmarshalKeystore decodes a keystore and verifies its checksum. The
+// metadata is only authenticated by Decrypt. KDF parameters beyond the cost
+// limits are rejected with ErrKeystoreKDFCostExceeded.
+func UnmarshalKeystore(data []byte) (*Keystore, error) {
+	if len(data) < len(keystoreMagic)+1+keystoreChecksumSize || string(data[:len(keystoreMagic)]) != keystoreMagic {
+		return nil, ErrKeystoreMalformed
+	}
+	body, checksum := data[:len(data)-keystoreChecksumSize], data[len(data)-keystoreChecksumSize:]
+	if !bytes.Equal(checksum, keystoreChecksum(body)) {
+		return nil, ErrKeystoreCorrupted
+	}
+	if body[len(keystoreMagic)] != keystoreVersion {
+		return nil, ErrKeystoreVersion
+	}
+
+	kdf, rest, err := decodeString(body[len(keystoreMagic)+1:])
+	if err != nil {
+		return nil, ErrKeystoreMalformed
+	}
+	k := &Keystore{}
+	if k.KDF, err = ParseKDFParams(string(kdf)); err != nil || k.KDF.String() != string(kdf) || k.KDF.Algorithm == KDFNone {
+		return nil, ErrKeystoreMalformed
+	}
+	if err := checkKeystoreKDFCost(k.KDF); err != nil {
+		return nil, err
+	}
+	if len(rest) < keystoreSaltSize+chacha20poly1305.NonceSizeX+2 {
+		return nil, ErrKeystoreMalformed
+	}
+	k.salt, rest = bytes.Clone(rest[:keystoreSaltSize]), rest[keystoreSaltSize:]
+	k.nonce, rest = bytes.Clone(rest[:chacha20poly1305.NonceSizeX]), rest[chacha20poly1305.NonceSizeX:]
+	k.Metadata.Profile, k.Metadata.Network = Profile(rest[0]), Network(rest[1])
+
+	label, rest, err := decodeString(rest[2:])
+	if err != nil {
+		return nil, ErrKeystoreMalformed
+	}
+	context, rest, err := decodeString(rest)
+	if err != nil || len(rest) != 16+keystoreSeedSize+chacha20poly1305.Overhead {
+		return nil, ErrKeystoreMalformed
+	}
+	k.Metadata.Label = string(label)
+	k.Metadata.CustomizationContext = bytes.Clone(context)
+	if seconds := binary.BigEndian.Uint64(rest); seconds != 0 {
+		k.Metadata.CreationTime = time.Unix(int64(seconds), 0).UTC()
+	}
+	k.Metadata.CreationHeight = binary.BigEndian.Uint64(rest[8:])
+	k.ciphertext = bytes.Clone(rest[16:])
+	if err := k.Metadata.Validate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *Keystore) encrypt(entropySeed []byte, password string) error {
+	if err := checkKeystoreKDFCost(k.KDF); err != nil {
+		return err
+	}
+	k.salt = make([]byte, keystoreSaltSize)
+	k.nonce = make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(k.salt); err != nil {
+		return err
+	}
+	if _, err := rand.Read(k.nonce); err != nil {
+		return err
+	}
+	aead, err := k.aead(password)
+	if err != nil {
+		return err
+	}
+	k.ciphertext = aead.Seal(nil, k.nonce, entropySeed, k.header())
+	return nil
+}
+
+func (k *Keystore) aead(password string) (cipher.AEAD, error) {
+	key, err := k.KDF.Derive(NormalizePassphrase(password), k.salt, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	return chacha20poly1305.NewX(key)
+}
+
+// header returns the encoding up to the ciphertext, the associated data of the
+// encryption.
+func (k *Keystore) header() []byte {
+	m := k.Metadata
+	data := []byte(keystoreMagic)
+	data = append(data, keystoreVersion)
+	data = append(data, encodeString([]byte(k.KDF.String()))...)
+	data = append(data, k.salt...)
+	data = append(data, k.nonce...)
+	data = append(data, byte(m.Profile), byte(m.Network))
+	data = append(data, encodeString([]byte(m.Label))...)
+	data = append(data, encodeString(m.CustomizationContext)...)
+	var seconds uint64
+	if !m.CreationTime.IsZero() {
+		seconds = uint64(m.CreationTime.Unix())
+	}
+	data = binary.BigEndian.AppendUint64(data, seconds)
+	return binary.BigEndian.AppendUint64(data, m.CreationHeight)
+}
+
+// checkKeystoreKDFCost checks the KDF parameters against the cost limits.
+func checkKeystoreKDFCost(p KDFParams) error {
+	switch p.Algorithm {
+	case KDFArgon2id:
+		if uint64(p.Memory)*1024 > MaxKeystoreKDFMemory || p.Time > MaxKeystoreArgon2idTime {
+			return ErrKeystoreKDFCostExceeded
+		}
+	case KDFScrypt:
+		if p.LogN >= 32 || uint64(p.R) > MaxKeystoreKDFMemory/128 ||
+			128*uint64(p.R)<<p.LogN > MaxKeystoreKDFMemory || p.P > MaxKeystoreScryptP {
+			return ErrKeystoreKDFCostExceeded
+		}
+	}
+	return nil
+}
+
+func keystoreChecksum(data []byte) []byte {
+	sum := sha3.Sum256(data)
+	return sum[:keystoreChecksumSize]
+}
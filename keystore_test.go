@@ -0,0 +1,223 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+// Cheap parameters, so that the tests run fast.
+var testKeystoreKDFParams = []aip11.KDFParams{
+	{Algorithm: aip11.KDFArgon2id, Time: 1, Memory: 64, Threads: 1},
+	{Algorithm: aip11.KDFScrypt, LogN: 4, R: 8, P: 1},
+}
+
+func ExampleNewKeystore() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	keystore, _ := aip11.NewKeystore(entropySeed, "correct horse battery staple", aip11.DefaultArgon2idParams(), aip11.KeystoreMetadata{
+		Label:   "savings",
+		Profile: aip11.ProfileAIP11,
+		Network: aip11.Mainnet,
+	})
+	data, _ := keystore.MarshalBinary()
+
+	imported, _ := aip11.UnmarshalKeystore(data)
+	_, err := imported.Decrypt("wrong password")
+	fmt.Println(imported.Metadata.Label, imported.KDF, err)
+	// Output: savings argon2id:t=3,m=65536,p=4 keystore password is incorrect
+}
+
+func TestKeystore(t *testing.T) {
+	entropySeed, err := hex.DecodeString(getAIP11Vector()[0].entropySeed)
+	assert.NoError(t, err, "Entropy seed should be decoded correctly")
+	metadata := aip11.KeystoreMetadata{
+		Label:                "cold storage",
+		Profile:              aip11.ProfileAIP15,
+		Network:              aip11.Testnet,
+		CustomizationContext: aip11.NewContextBuilder().String("Account").Uint(1).Build(),
+		CreationTime:         time.Date(2025, 11, 5, 14, 20, 0, 0, time.UTC),
+		CreationHeight:       123456,
+	}
+
+	for _, params := range testKeystoreKDFParams {
+		t.Run(params.String(), func(t *testing.T) {
+			keystore, err := aip11.NewKeystore(entropySeed, "pässwörd", params, metadata)
+			assert.NoError(t, err, "Keystore should be created correctly")
+			data, err := keystore.MarshalBinary()
+			assert.NoError(t, err, "Keystore should be encoded correctly")
+
+			imported, err := aip11.UnmarshalKeystore(data)
+			assert.NoError(t, err, "Keystore should be decoded correctly")
+			assert.Equal(t, metadata, imported.Metadata, "Metadata should be decoded correctly")
+			assert.Equal(t, params, imported.KDF, "KDF params should be decoded correctly")
+
+			decrypted, err := imported.Decrypt("pässwörd")
+			assert.NoError(t, err, "Keystore should be decrypted correctly")
+			assert.Equal(t, entropySeed, decrypted, "Entropy seed should be decrypted correctly")
+			decrypted, err = imported.Decrypt("pa\u0308sswo\u0308rd")
+			assert.NoError(t, err, "Unnormalized password should be accepted correctly")
+			assert.Equal(t, entropySeed, decrypted, "Entropy seed should be decrypted correctly")
+
+			_, err = imported.Decrypt("password")
+			assert.ErrorIs(t, err, aip11.ErrKeystorePassword, "Wrong password should be rejected correctly")
+		})
+	}
+}
+
+func TestKeystoreChangePassword(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	metadata := aip11.KeystoreMetadata{Label: "hot", Profile: aip11.ProfileAIP11, Network: aip11.Mainnet}
+	keystore, err := aip11.NewKeystore(entropySeed, "old", testKeystoreKDFParams[0], metadata)
+	assert.NoError(t, err, "Keystore should be created correctly")
+	before, err := keystore.MarshalBinary()
+	assert.NoError(t, err, "Keystore should be encoded correctly")
+
+	err = keystore.ChangePassword("wrong", "new", testKeystoreKDFParams[1])
+	assert.ErrorIs(t, err, aip11.ErrKeystorePassword, "Wrong old password should be rejected correctly")
+	err = keystore.ChangePassword("old", "new", aip11.KDFParams{})
+	assert.ErrorIs(t, err, aip11.ErrKDFParamsInvalid, "Missing KDF should be rejected correctly")
+	unchanged, err := keystore.MarshalBinary()
+	assert.NoError(t, err, "Keystore should be encoded correctly")
+	assert.Equal(t, before, unchanged, "Failed password change should keep the keystore correctly")
+
+	assert.NoError(t, keystore.ChangePassword("old", "new", testKeystoreKDFParams[1]), "Password should be changed correctly")
+	assert.Equal(t, metadata, keystore.Metadata, "Password change should keep the metadata correctly")
+	assert.Equal(t, testKeystoreKDFParams[1], keystore.KDF, "Password change should update the KDF params correctly")
+	_, err = keystore.Decrypt("old")
+	assert.ErrorIs(t, err, aip11.ErrKeystorePassword, "Old password should be rejected correctly")
+	decrypted, err := keystore.Decrypt("new")
+	assert.NoError(t, err, "Keystore should be decrypted correctly")
+	assert.Equal(t, entropySeed, decrypted, "Password change should keep the entropy seed correctly")
+}
+
+func TestKeystoreIntegrity(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	metadata := aip11.KeystoreMetadata{Label: "main", Profile: aip11.ProfileAIP11, Network: aip11.Mainnet, CreationHeight: 7}
+	keystore, err := aip11.NewKeystore(entropySeed, "password", testKeystoreKDFParams[0], metadata)
+	assert.NoError(t, err, "Keystore should be created correctly")
+	data, err := keystore.MarshalBinary()
+	assert.NoError(t, err, "Keystore should be encoded correctly")
+
+	withChecksum := func(body []byte) []byte {
+		sum := sha3.Sum256(body)
+		return append(body, sum[:4]...)
+	}
+	body := data[:len(data)-4]
+
+	for i := range data {
+		corrupted := append([]byte{}, data...)
+		corrupted[i] ^= 0x01
+		_, err := aip11.UnmarshalKeystore(corrupted)
+		assert.Error(t, err, "Corrupted keystore should be rejected correctly")
+		if i >= 8 {
+			assert.ErrorIs(t, err, aip11.ErrKeystoreCorrupted, "Corruption should be reported correctly")
+		}
+	}
+
+	// A modification with a recomputed checksum is only caught by decryption.
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-48-1] ^= 0x01 // creation height
+	imported, err := aip11.UnmarshalKeystore(withChecksum(tampered))
+	assert.NoError(t, err, "Tampered keystore should be decoded correctly")
+	assert.Equal(t, uint64(6), imported.Metadata.CreationHeight, "Tampered height should be decoded correctly")
+	_, err = imported.Decrypt("password")
+	assert.ErrorIs(t, err, aip11.ErrKeystorePassword, "Tampered metadata should be rejected correctly")
+
+	imported, err = aip11.UnmarshalKeystore(data)
+	assert.NoError(t, err, "Keystore should be decoded correctly")
+	imported.Metadata.Label = "other"
+	_, err = imported.Decrypt("password")
+	assert.ErrorIs(t, err, aip11.ErrKeystorePassword, "Modified metadata should be rejected correctly")
+
+	version := append([]byte{}, body...)
+	version[8] = 0x02
+	_, err = aip11.UnmarshalKeystore(withChecksum(version))
+	assert.ErrorIs(t, err, aip11.ErrKeystoreVersion, "Unknown version should be rejected correctly")
+
+	_, err = aip11.UnmarshalKeystore(withChecksum(append(append([]byte{}, body...), 0x00)))
+	assert.ErrorIs(t, err, aip11.ErrKeystoreMalformed, "Trailing bytes should be rejected correctly")
+	_, err = aip11.UnmarshalKeystore([]byte("AIP11KST"))
+	assert.ErrorIs(t, err, aip11.ErrKeystoreMalformed, "Truncated keystore should be rejected correctly")
+}
+
+func TestNewKeystoreRejects(t *testing.T) {
+	entropySeed := make([]byte, 32)
+	valid := aip11.KeystoreMetadata{Profile: aip11.ProfileAIP11, Network: aip11.Mainnet}
+
+	testCases := []struct {
+		name        string
+		entropySeed []byte
+		params      aip11.KDFParams
+		metadata    aip11.KeystoreMetadata
+		expected    error
+	}{
+		{"short entropy seed", entropySeed[:31], testKeystoreKDFParams[0], valid, aip11.ErrEntropySeedInvalid},
+		{"no KDF", entropySeed, aip11.KDFParams{}, valid, aip11.ErrKDFParamsInvalid},
+		{"costly KDF", entropySeed, aip11.KDFParams{Algorithm: aip11.KDFArgon2id, Time: 1, Memory: 1 << 23, Threads: 1}, valid, aip11.ErrKeystoreKDFCostExceeded},
+		{"unknown profile", entropySeed, testKeystoreKDFParams[0], aip11.KeystoreMetadata{Network: aip11.Mainnet}, aip11.ErrProfileUnknown},
+		{"unknown network", entropySeed, testKeystoreKDFParams[0], aip11.KeystoreMetadata{Profile: aip11.ProfileAIP11, Network: 9}, aip11.ErrNetworkUnknown},
+		{"legacy with context", entropySeed, testKeystoreKDFParams[0],
+			aip11.KeystoreMetadata{Profile: aip11.ProfileLegacy, Network: aip11.Mainnet, CustomizationContext: []byte("ctx")}, aip11.ErrKeystoreMetadataInvalid},
+		{"creation time before epoch", entropySeed, testKeystoreKDFParams[0],
+			aip11.KeystoreMetadata{Profile: aip11.ProfileAIP11, Network: aip11.Mainnet, CreationTime: time.Unix(-1, 0)}, aip11.ErrKeystoreMetadataInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := aip11.NewKeystore(tc.entropySeed, "password", tc.params, tc.metadata)
+			assert.ErrorIs(t, err, tc.expected, "Invalid keystore should be rejected correctly")
+		})
+	}
+}
+
+func TestUnmarshalKeystoreKDF(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	metadata := aip11.KeystoreMetadata{Profile: aip11.ProfileAIP11, Network: aip11.Mainnet}
+	keystore, err := aip11.NewKeystore(entropySeed, "password", testKeystoreKDFParams[0], metadata)
+	assert.NoError(t, err, "Keystore should be created correctly")
+	data, err := keystore.MarshalBinary()
+	assert.NoError(t, err, "Keystore should be encoded correctly")
+
+	// withKDF replaces the KDF string, all strings here being shorter than 32
+	// bytes, so that encode_string prefixes them with 0x01 and their bit length.
+	kdfStart := len("AIP11KST") + 1
+	kdfEnd := kdfStart + 2 + len(testKeystoreKDFParams[0].String())
+	withKDF := func(kdf string) []byte {
+		body := append([]byte{}, data[:kdfStart]...)
+		body = append(body, 0x01, byte(8*len(kdf)))
+		body = append(body, kdf...)
+		body = append(body, data[kdfEnd:len(data)-4]...)
+		sum := sha3.Sum256(body)
+		return append(body, sum[:4]...)
+	}
+
+	imported, err := aip11.UnmarshalKeystore(withKDF(testKeystoreKDFParams[0].String()))
+	assert.NoError(t, err, "Keystore should be decoded correctly")
+	assert.Equal(t, testKeystoreKDFParams[0], imported.KDF, "KDF params should be decoded correctly")
+	imported, err = aip11.UnmarshalKeystore(withKDF("scrypt:logn=10,r=8,p=1"))
+	assert.NoError(t, err, "KDF within the cost limits should be accepted")
+	_, err = imported.Decrypt("password")
+	assert.ErrorIs(t, err, aip11.ErrKeystorePassword, "Modified KDF should be rejected correctly")
+
+	for kdf, expected := range map[string]error{
+		"none":                          aip11.ErrKeystoreMalformed,
+		"argon2id:t=01,m=64,p=1":        aip11.ErrKeystoreMalformed,
+		"argon2id:t=1,m=64":             aip11.ErrKeystoreMalformed,
+		"bcrypt:cost=10":                aip11.ErrKeystoreMalformed,
+		"argon2id:t=1,m=4294967295,p=1": aip11.ErrKeystoreKDFCostExceeded,
+		"argon2id:t=100,m=64,p=1":       aip11.ErrKeystoreKDFCostExceeded,
+		"scrypt:logn=62,r=8,p=1":        aip11.ErrKeystoreKDFCostExceeded,
+		"scrypt:logn=23,r=8,p=1":        aip11.ErrKeystoreKDFCostExceeded,
+		"scrypt:logn=4,r=8,p=1000":      aip11.ErrKeystoreKDFCostExceeded,
+	} {
+		_, err := aip11.UnmarshalKeystore(withKDF(kdf))
+		assert.ErrorIs(t, err, expected, fmt.Sprintf("KDF %s should be rejected correctly", kdf))
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package aip11
+
+import (
+	"os"
+)
+
+func lockFile(f *os.File) error {
+	return ErrFileLockUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return ErrFileLockUnsupported
+}
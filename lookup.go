@@ -0,0 +1,265 @@
+package aip11
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// This file provides reverse lookup from a public rand to its sequence number.
+//
+// PublicRandIndex pre-derives the public rands of a window of sequence numbers
+// [0, Len()) and stores the first 8 bytes of each in a hash table, so that a
+// public rand seen on chain is mapped back to its sequence number in O(1). A
+// hit is confirmed by re-deriving the public rand, so prefix collisions never
+// yield a wrong answer.
+//
+// The index persists to disk as
+//
+//	magic "AIP11PRI" || version (1 byte) || rootTag (16 bytes) || count (4 bytes, big-endian) ||
+//	prefix_0 || ... || prefix_{count-1} (8 bytes each) || SHA3-256 of all preceding bytes
+//
+// where rootTag is the first 16 bytes of PRF(publicRandRootSeed, "PublicRandIndexTag"),
+// so that an index is never loaded for the wrong root seed.
+
+// Errors
+
+var (
+	ErrPublicRandIndexCorrupted    = errors.New("public rand index is corrupted")
+	ErrPublicRandIndexRootMismatch = errors.New("public rand index belongs to a different public rand root seed")
+	ErrPublicRandIndexVersion      = errors.New("public rand index version is not supported")
+)
+
+const (
+	publicRandIndexMagic      = "AIP11PRI"
+	publicRandIndexVersion    = 0x01
+	publicRandIndexTagLabel   = "PublicRandIndexTag"
+	publicRandIndexTagSize    = 16
+	publicRandIndexPrefixSize = 8
+
+	// publicRandIndexBatchSize is the number of public rands Extend derives
+	// per call to DerivePublicRandRange.
+	publicRandIndexBatchSize = 1 << 16
+)
+
+// PublicRandIndex maps public rands of a window of sequence numbers back to
+// their sequence numbers. It is safe for concurrent use.
+type PublicRandIndex struct {
+	mu sync.RWMutex
+
+	publicRandRootSeed []byte
+	deriver            *publicRandDeriver
+
+	// prefixes holds the prefix of every public rand in the window, in
+	// sequence number order.
+	prefixes []uint64
+	// table maps a prefix to the first sequence number with that prefix;
+	// collisions holds the further ones.
+	table      map[uint64]uint32
+	collisions map[uint64][]uint32
+}
+
+// NewPublicRandIndex creates an empty index for the public rand root seed.
+func NewPublicRandIndex(publicRandRootSeed []byte) (*PublicRandIndex, error) {
+	deriver, err := newPublicRandDeriver(publicRandRootSeed)
+	if err != nil {
+		return nil, err
+	}
+	root := make([]byte, len(publicRandRootSeed))
+	copy(root, publicRandRootSeed)
+	return &PublicRandIndex{
+		publicRandRootSeed: root,
+		deriver:            deriver,
+		table:              map[uint64]uint32{},
+		collisions:         map[uint64][]uint32{},
+	}, nil
+}
+
+// Len returns the size of the window: the index covers the sequence numbers
+// 0 to Len()-1.
+func (ix *PublicRandIndex) Len() uint64 {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return uint64(len(ix.prefixes))
+}
+
+// Extend derives the next count public rands and adds them to the index.
+func (ix *PublicRandIndex) Extend(ctx context.Context, count uint32) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	start := uint64(len(ix.prefixes))
+	if count > 0 && start+uint64(count)-1 > uint64(MaxSeqNo) {
+		return ErrSeqNoRangeInvalid
+	}
+
+	buf := make([]byte, min(count, publicRandIndexBatchSize)*PublicRandSize)
+	for done := uint32(0); done < count; {
+		n := min(count-done, publicRandIndexBatchSize)
+		seqNo := uint32(start) + done
+		if err := DerivePublicRandRange(ctx, ix.publicRandRootSeed, seqNo, n, buf); err != nil {
+			return err
+		}
+		for i := uint32(0); i < n; i++ {
+			ix.add(binary.BigEndian.Uint64(buf[i*PublicRandSize:]))
+		}
+		done += n
+	}
+	return nil
+}
+
+// add appends the prefix of the next sequence number. The caller must hold mu.
+func (ix *PublicRandIndex) add(prefix uint64) {
+	seqNo := uint32(len(ix.prefixes))
+	ix.prefixes = append(ix.prefixes, prefix)
+	if _, ok := ix.table[prefix]; ok {
+		ix.collisions[prefix] = append(ix.collisions[prefix], seqNo)
+		return
+	}
+	ix.table[prefix] = seqNo
+}
+
+// Lookup returns the sequence number of the public rand if it lies in the window.
+func (ix *PublicRandIndex) Lookup(publicRand []byte) (uint32, bool) {
+	if len(publicRand) != PublicRandSize {
+		return 0, false
+	}
+	prefix := binary.BigEndian.Uint64(publicRand)
+
+	ix.mu.RLock()
+	seqNo, ok := ix.table[prefix]
+	candidates := ix.collisions[prefix]
+	ix.mu.RUnlock()
+	if !ok {
+		return 0, false
+	}
+
+	derived := make([]byte, PublicRandSize)
+	for _, candidate := range append([]uint32{seqNo}, candidates...) {
+		ix.deriver.derive(candidate, derived)
+		if bytes.Equal(derived, publicRand) {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
+
+// WriteTo writes the index to w.
+func (ix *PublicRandIndex) WriteTo(w io.Writer) (int64, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	h := sha3.New256()
+	bw := bufio.NewWriter(io.MultiWriter(w, h))
+	header := make([]byte, 0, len(publicRandIndexMagic)+1+publicRandIndexTagSize+4)
+	header = append(header, publicRandIndexMagic...)
+	header = append(header, publicRandIndexVersion)
+	header = append(header, publicRandIndexTag(ix.publicRandRootSeed)...)
+	header = binary.BigEndian.AppendUint32(header, uint32(len(ix.prefixes)))
+	if _, err := bw.Write(header); err != nil {
+		return 0, err
+	}
+	var entry [publicRandIndexPrefixSize]byte
+	for _, prefix := range ix.prefixes {
+		binary.BigEndian.PutUint64(entry[:], prefix)
+		if _, err := bw.Write(entry[:]); err != nil {
+			return 0, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(h.Sum(nil)); err != nil {
+		return 0, err
+	}
+	return int64(len(header) + len(ix.prefixes)*publicRandIndexPrefixSize + h.Size()), nil
+}
+
+// ReadPublicRandIndex reads an index written by WriteTo for the public rand
+// root seed.
+func ReadPublicRandIndex(r io.Reader, publicRandRootSeed []byte) (*PublicRandIndex, error) {
+	ix, err := NewPublicRandIndex(publicRandRootSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha3.New256()
+	br := bufio.NewReader(r)
+	tr := io.TeeReader(br, h)
+
+	header := make([]byte, len(publicRandIndexMagic)+1+publicRandIndexTagSize+4)
+	if _, err := io.ReadFull(tr, header); err != nil {
+		return nil, ErrPublicRandIndexCorrupted
+	}
+	if string(header[:len(publicRandIndexMagic)]) != publicRandIndexMagic {
+		return nil, ErrPublicRandIndexCorrupted
+	}
+	rest := header[len(publicRandIndexMagic):]
+	if rest[0] != publicRandIndexVersion {
+		return nil, ErrPublicRandIndexVersion
+	}
+	tag, rest := rest[1:1+publicRandIndexTagSize], rest[1+publicRandIndexTagSize:]
+	count := binary.BigEndian.Uint32(rest)
+
+	var entry [publicRandIndexPrefixSize]byte
+	for i := uint32(0); i < count; i++ {
+		if _, err := io.ReadFull(tr, entry[:]); err != nil {
+			return nil, ErrPublicRandIndexCorrupted
+		}
+		ix.add(binary.BigEndian.Uint64(entry[:]))
+	}
+
+	checksum := make([]byte, h.Size())
+	sum := h.Sum(nil)
+	if _, err := io.ReadFull(br, checksum); err != nil || !bytes.Equal(checksum, sum) {
+		return nil, ErrPublicRandIndexCorrupted
+	}
+	if !bytes.Equal(tag, publicRandIndexTag(publicRandRootSeed)) {
+		return nil, ErrPublicRandIndexRootMismatch
+	}
+	return ix, nil
+}
+
+// Save writes the index to the file at path, replacing it atomically.
+func (ix *PublicRandIndex) Save(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := ix.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadPublicRandIndex reads the index saved at path for the public rand root seed.
+func LoadPublicRandIndex(path string, publicRandRootSeed []byte) (*PublicRandIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadPublicRandIndex(f, publicRandRootSeed)
+}
+
+func publicRandIndexTag(publicRandRootSeed []byte) []byte {
+	return PRF(publicRandRootSeed, []byte(publicRandIndexTagLabel))[:publicRandIndexTagSize]
+}
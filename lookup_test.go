@@ -0,0 +1,106 @@
+package aip11_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExamplePublicRandIndex() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	publicRandRootSeed, _ := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+
+	index, _ := aip11.NewPublicRandIndex(publicRandRootSeed)
+	_ = index.Extend(context.Background(), 10000)
+
+	publicRand, _ := aip11.DerivePublicRand(publicRandRootSeed, 4242)
+	seqNo, ok := index.Lookup(publicRand)
+	fmt.Println(seqNo, ok)
+	// Output: 4242 true
+}
+
+func TestPublicRandIndex(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	publicRandRootSeed[0] = 1
+
+	index, err := aip11.NewPublicRandIndex(publicRandRootSeed)
+	assert.NoError(t, err, "Index should be created correctly")
+	assert.NoError(t, index.Extend(context.Background(), 1000), "Index should be extended correctly")
+	assert.Equal(t, uint64(1000), index.Len(), "Index should cover the window")
+
+	for _, seqNo := range []uint32{0, 1, 500, 999} {
+		publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, seqNo)
+		assert.NoError(t, err, "Public rand should be generated correctly")
+		result, ok := index.Lookup(publicRand)
+		assert.True(t, ok, "Public rand %d should be found", seqNo)
+		assert.Equal(t, seqNo, result, "Sequence number should be the same")
+	}
+
+	outside, err := aip11.DerivePublicRand(publicRandRootSeed, 1000)
+	assert.NoError(t, err, "Public rand should be generated correctly")
+	_, ok := index.Lookup(outside)
+	assert.False(t, ok, "Public rand outside the window should not be found")
+
+	assert.NoError(t, index.Extend(context.Background(), 500), "Index should be extended incrementally")
+	assert.Equal(t, uint64(1500), index.Len(), "Index should cover the extended window")
+	result, ok := index.Lookup(outside)
+	assert.True(t, ok, "Public rand should be found after extension")
+	assert.Equal(t, uint32(1000), result, "Sequence number should be the same")
+
+	// A public rand sharing the prefix of an indexed one is not a hit.
+	forged, err := aip11.DerivePublicRand(publicRandRootSeed, 7)
+	assert.NoError(t, err, "Public rand should be generated correctly")
+	forged[63] ^= 1
+	_, ok = index.Lookup(forged)
+	assert.False(t, ok, "Public rand with a matching prefix only should not be found")
+	_, ok = index.Lookup(forged[:32])
+	assert.False(t, ok, "Short public rand should not be found")
+
+	_, err = aip11.NewPublicRandIndex(publicRandRootSeed[:32])
+	assert.ErrorIs(t, err, aip11.ErrPublicRandRootSeedInvalid, "Invalid public rand root seed should be rejected")
+}
+
+func TestPublicRandIndexPersistence(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	publicRandRootSeed[0] = 2
+
+	index, err := aip11.NewPublicRandIndex(publicRandRootSeed)
+	assert.NoError(t, err, "Index should be created correctly")
+	assert.NoError(t, index.Extend(context.Background(), 300), "Index should be extended correctly")
+
+	path := filepath.Join(t.TempDir(), "publicrand.idx")
+	assert.NoError(t, index.Save(path), "Index should be saved correctly")
+
+	loaded, err := aip11.LoadPublicRandIndex(path, publicRandRootSeed)
+	assert.NoError(t, err, "Index should be loaded correctly")
+	assert.Equal(t, uint64(300), loaded.Len(), "Loaded index should cover the window")
+	publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, 299)
+	assert.NoError(t, err, "Public rand should be generated correctly")
+	seqNo, ok := loaded.Lookup(publicRand)
+	assert.True(t, ok, "Public rand should be found in the loaded index")
+	assert.Equal(t, uint32(299), seqNo, "Sequence number should be the same")
+
+	otherRootSeed := make([]byte, 64)
+	_, err = aip11.LoadPublicRandIndex(path, otherRootSeed)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandIndexRootMismatch, "Index of another root seed should be rejected")
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err, "Index file should be read correctly")
+	for _, offset := range []int{0, 40, len(data) - 1} {
+		t.Run(fmt.Sprintf("corrupted byte %d", offset), func(t *testing.T) {
+			corrupted := bytes.Clone(data)
+			corrupted[offset] ^= 0x80
+			_, err := aip11.ReadPublicRandIndex(bytes.NewReader(corrupted), publicRandRootSeed)
+			assert.ErrorIs(t, err, aip11.ErrPublicRandIndexCorrupted, "Corrupted index should be rejected")
+		})
+	}
+	_, err = aip11.ReadPublicRandIndex(bytes.NewReader(data[:len(data)-10]), publicRandRootSeed)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandIndexCorrupted, "Truncated index should be rejected")
+}
@@ -0,0 +1,222 @@
+package aip11
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// This file provides a Merkle commitment over a range of public rands, so that
+// auditors can check that deposit addresses belong to one account without
+// receiving any root seed.
+//
+// With H = SHA3-256, the tree over the public rands with sequence numbers
+// start, ..., start+count-1 is built from
+//
+//	leaf(seqNo, publicRand) = H(0x00 || uint32_be(seqNo) || publicRand)
+//	node(left, right)       = H(0x01 || left || right)
+//
+// where a node without a sibling at the end of a level is promoted to the next
+// level unchanged. The exported commitment binds the range as well:
+//
+//	root = H(0x02 || uint32_be(start) || uint32_be(count) || treeRoot)
+
+// Errors
+
+var (
+	ErrMerkleRangeEmpty     = errors.New("public rand range must not be empty")
+	ErrMerkleSeqNoNotInTree = errors.New("sequence number is not in the committed range")
+	ErrMerkleProofInvalid   = errors.New("inclusion proof is invalid")
+)
+
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+	merkleRootPrefix = 0x02
+
+	// MerkleHashSize is the size of the commitment root and of proof hashes.
+	MerkleHashSize = 32
+)
+
+// PublicRandTree is a Merkle tree over a range of public rands.
+type PublicRandTree struct {
+	start  uint32
+	levels [][][MerkleHashSize]byte
+	root   [MerkleHashSize]byte
+}
+
+// InclusionProof proves that a (seqNo, publicRand) pair is committed to by a
+// PublicRandTree root.
+type InclusionProof struct {
+	Start    uint32
+	Count    uint32
+	Siblings [][MerkleHashSize]byte
+}
+
+// CommitPublicRandRange derives the public rands with sequence numbers start
+// to start+count-1 and builds the Merkle tree over them.
+func CommitPublicRandRange(ctx context.Context, publicRandRootSeed []byte, start, count uint32) (*PublicRandTree, error) {
+	if count == 0 {
+		return nil, ErrMerkleRangeEmpty
+	}
+	publicRands := make([]byte, uint64(count)*PublicRandSize)
+	if err := DerivePublicRandRange(ctx, publicRandRootSeed, start, count, publicRands); err != nil {
+		return nil, err
+	}
+
+	leaves := make([][MerkleHashSize]byte, count)
+	for i := range leaves {
+		leaves[i] = merkleLeaf(start+uint32(i), publicRands[i*PublicRandSize:(i+1)*PublicRandSize])
+	}
+	levels := [][][MerkleHashSize]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][MerkleHashSize]byte, (len(level)+1)/2)
+		for i := range next {
+			if 2*i+1 < len(level) {
+				next[i] = merkleNode(level[2*i], level[2*i+1])
+			} else {
+				next[i] = level[2*i]
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	t := &PublicRandTree{start: start, levels: levels}
+	t.root = merkleRoot(start, count, levels[len(levels)-1][0])
+	return t, nil
+}
+
+// Root returns the commitment to the range.
+func (t *PublicRandTree) Root() []byte {
+	root := t.root
+	return root[:]
+}
+
+// Start returns the first sequence number of the range.
+func (t *PublicRandTree) Start() uint32 {
+	return t.start
+}
+
+// Count returns the number of public rands in the range.
+func (t *PublicRandTree) Count() uint32 {
+	return uint32(len(t.levels[0]))
+}
+
+// Proof returns the inclusion proof of the public rand with the given
+// sequence number.
+func (t *PublicRandTree) Proof(seqNo uint32) (*InclusionProof, error) {
+	if seqNo < t.start || uint64(seqNo-t.start) >= uint64(t.Count()) {
+		return nil, ErrMerkleSeqNoNotInTree
+	}
+
+	proof := &InclusionProof{Start: t.start, Count: t.Count(), Siblings: [][MerkleHashSize]byte{}}
+	index := int(seqNo - t.start)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof.Siblings = append(proof.Siblings, level[sibling])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyPublicRandInclusion checks that the proof shows the public rand with
+// the given sequence number to be committed to by root.
+func VerifyPublicRandInclusion(root []byte, seqNo uint32, publicRand []byte, proof *InclusionProof) error {
+	if proof == nil || proof.Count == 0 || len(root) != MerkleHashSize || len(publicRand) != PublicRandSize {
+		return ErrMerkleProofInvalid
+	}
+	if seqNo < proof.Start || uint64(seqNo-proof.Start) >= uint64(proof.Count) {
+		return ErrMerkleProofInvalid
+	}
+
+	node := merkleLeaf(seqNo, publicRand)
+	index, size := uint64(seqNo-proof.Start), uint64(proof.Count)
+	siblings := proof.Siblings
+	for size > 1 {
+		if index^1 < size {
+			if len(siblings) == 0 {
+				return ErrMerkleProofInvalid
+			}
+			if index%2 == 0 {
+				node = merkleNode(node, siblings[0])
+			} else {
+				node = merkleNode(siblings[0], node)
+			}
+			siblings = siblings[1:]
+		}
+		index /= 2
+		size = (size + 1) / 2
+	}
+	if len(siblings) != 0 {
+		return ErrMerkleProofInvalid
+	}
+
+	expected := merkleRoot(proof.Start, proof.Count, node)
+	if string(expected[:]) != string(root) {
+		return ErrMerkleProofInvalid
+	}
+	return nil
+}
+
+// MarshalBinary encodes the proof as
+//
+//	uint32_be(start) || uint32_be(count) || sibling_0 || ... || sibling_{n-1}
+func (p *InclusionProof) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 8+len(p.Siblings)*MerkleHashSize)
+	data = binary.BigEndian.AppendUint32(data, p.Start)
+	data = binary.BigEndian.AppendUint32(data, p.Count)
+	for _, sibling := range p.Siblings {
+		data = append(data, sibling[:]...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (p *InclusionProof) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 || (len(data)-8)%MerkleHashSize != 0 || (len(data)-8)/MerkleHashSize > 32 {
+		return ErrMerkleProofInvalid
+	}
+	p.Start = binary.BigEndian.Uint32(data)
+	p.Count = binary.BigEndian.Uint32(data[4:])
+	p.Siblings = make([][MerkleHashSize]byte, (len(data)-8)/MerkleHashSize)
+	for i := range p.Siblings {
+		copy(p.Siblings[i][:], data[8+i*MerkleHashSize:])
+	}
+	return nil
+}
+
+func merkleLeaf(seqNo uint32, publicRand []byte) [MerkleHashSize]byte {
+	h := sha3.New256()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(binary.BigEndian.AppendUint32(nil, seqNo))
+	h.Write(publicRand)
+	return merkleSum(h.Sum(nil))
+}
+
+func merkleNode(left, right [MerkleHashSize]byte) [MerkleHashSize]byte {
+	h := sha3.New256()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left[:])
+	h.Write(right[:])
+	return merkleSum(h.Sum(nil))
+}
+
+func merkleRoot(start, count uint32, treeRoot [MerkleHashSize]byte) [MerkleHashSize]byte {
+	h := sha3.New256()
+	h.Write([]byte{merkleRootPrefix})
+	h.Write(binary.BigEndian.AppendUint32(nil, start))
+	h.Write(binary.BigEndian.AppendUint32(nil, count))
+	h.Write(treeRoot[:])
+	return merkleSum(h.Sum(nil))
+}
+
+func merkleSum(sum []byte) [MerkleHashSize]byte {
+	var out [MerkleHashSize]byte
+	copy(out[:], sum)
+	return out
+}
@@ -0,0 +1,123 @@
+package aip11_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleCommitPublicRandRange() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	publicRandRootSeed, _ := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+
+	// The account holder publishes the root and hands out proofs.
+	tree, _ := aip11.CommitPublicRandRange(context.Background(), publicRandRootSeed, 0, 1000)
+	root := tree.Root()
+	proof, _ := tree.Proof(123)
+
+	// The auditor checks a deposit public rand against the root.
+	publicRand, _ := aip11.DerivePublicRand(publicRandRootSeed, 123)
+	fmt.Println(aip11.VerifyPublicRandInclusion(root, 123, publicRand, proof))
+	// Output: <nil>
+}
+
+func TestCommitPublicRandRange(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+
+	for count := uint32(1); count <= 17; count++ {
+		t.Run(fmt.Sprintf("count %d", count), func(t *testing.T) {
+			start := uint32(100)
+			tree, err := aip11.CommitPublicRandRange(context.Background(), publicRandRootSeed, start, count)
+			assert.NoError(t, err, "Tree should be built correctly")
+			assert.Equal(t, aip11.MerkleHashSize, len(tree.Root()), "Root should be a SHA3-256 hash")
+			assert.Equal(t, start, tree.Start(), "Start should be the same")
+			assert.Equal(t, count, tree.Count(), "Count should be the same")
+
+			for seqNo := start; seqNo < start+count; seqNo++ {
+				publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, seqNo)
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				proof, err := tree.Proof(seqNo)
+				assert.NoError(t, err, "Proof should be generated correctly")
+				assert.NoError(t, aip11.VerifyPublicRandInclusion(tree.Root(), seqNo, publicRand, proof), "Proof of %d should verify", seqNo)
+			}
+
+			_, err = tree.Proof(start - 1)
+			assert.ErrorIs(t, err, aip11.ErrMerkleSeqNoNotInTree, "Sequence number before the range should be rejected")
+			_, err = tree.Proof(start + count)
+			assert.ErrorIs(t, err, aip11.ErrMerkleSeqNoNotInTree, "Sequence number after the range should be rejected")
+		})
+	}
+
+	_, err := aip11.CommitPublicRandRange(context.Background(), publicRandRootSeed, 0, 0)
+	assert.ErrorIs(t, err, aip11.ErrMerkleRangeEmpty, "Empty range should be rejected")
+}
+
+func TestVerifyPublicRandInclusionRejects(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	tree, err := aip11.CommitPublicRandRange(context.Background(), publicRandRootSeed, 0, 10)
+	assert.NoError(t, err, "Tree should be built correctly")
+	root := tree.Root()
+
+	publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, 6)
+	assert.NoError(t, err, "Public rand should be generated correctly")
+	proof, err := tree.Proof(6)
+	assert.NoError(t, err, "Proof should be generated correctly")
+
+	otherPublicRand, err := aip11.DerivePublicRand(publicRandRootSeed, 10)
+	assert.NoError(t, err, "Public rand should be generated correctly")
+	otherTree, err := aip11.CommitPublicRandRange(context.Background(), publicRandRootSeed, 0, 11)
+	assert.NoError(t, err, "Tree should be built correctly")
+
+	tamperedSibling := *proof
+	tamperedSibling.Siblings = append([][aip11.MerkleHashSize]byte{}, proof.Siblings...)
+	tamperedSibling.Siblings[0][0] ^= 1
+	tamperedCount := *proof
+	tamperedCount.Count = 11
+	extraSibling := *proof
+	extraSibling.Siblings = append(append([][aip11.MerkleHashSize]byte{}, proof.Siblings...), [aip11.MerkleHashSize]byte{})
+
+	testCases := []struct {
+		name       string
+		root       []byte
+		seqNo      uint32
+		publicRand []byte
+		proof      *aip11.InclusionProof
+	}{
+		{name: "Wrong sequence number", root: root, seqNo: 7, publicRand: publicRand, proof: proof},
+		{name: "Wrong public rand", root: root, seqNo: 6, publicRand: otherPublicRand, proof: proof},
+		{name: "Wrong root", root: otherTree.Root(), seqNo: 6, publicRand: publicRand, proof: proof},
+		{name: "Tampered sibling", root: root, seqNo: 6, publicRand: publicRand, proof: &tamperedSibling},
+		{name: "Tampered count", root: root, seqNo: 6, publicRand: publicRand, proof: &tamperedCount},
+		{name: "Extra sibling", root: root, seqNo: 6, publicRand: publicRand, proof: &extraSibling},
+		{name: "Out of range", root: root, seqNo: 10, publicRand: otherPublicRand, proof: proof},
+		{name: "Nil proof", root: root, seqNo: 6, publicRand: publicRand, proof: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := aip11.VerifyPublicRandInclusion(tc.root, tc.seqNo, tc.publicRand, tc.proof)
+			assert.ErrorIs(t, err, aip11.ErrMerkleProofInvalid, "Invalid proof should be rejected")
+		})
+	}
+}
+
+func TestInclusionProofMarshalBinary(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	tree, err := aip11.CommitPublicRandRange(context.Background(), publicRandRootSeed, 5, 100)
+	assert.NoError(t, err, "Tree should be built correctly")
+	proof, err := tree.Proof(42)
+	assert.NoError(t, err, "Proof should be generated correctly")
+
+	data, err := proof.MarshalBinary()
+	assert.NoError(t, err, "Proof should be encoded correctly")
+	assert.Equal(t, 8+7*aip11.MerkleHashSize, len(data), "Proof should be compact")
+
+	decoded := &aip11.InclusionProof{}
+	assert.NoError(t, decoded.UnmarshalBinary(data), "Proof should be decoded correctly")
+	assert.Equal(t, proof, decoded, "Decoded proof should be the same")
+
+	assert.ErrorIs(t, decoded.UnmarshalBinary(data[:len(data)-1]), aip11.ErrMerkleProofInvalid, "Truncated proof should be rejected")
+}
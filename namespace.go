@@ -0,0 +1,87 @@
+package aip11
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// This file provides namespaces for deterministic public rands.
+//
+// MasterSeedToAccountPublicRandRootSeed gives one root seed per account, so all
+// public rands of an account share one sequence number space. A namespace
+// derives a separately labelled sub-root from the public rand root seed:
+//
+//	namespaceRootSeed = PRF(publicRandRootSeed, "PublicRandNamespace" || encode_string(namespace))
+//
+// and public rands are derived from the sub-root with DerivePublicRand as
+// usual. Each namespace therefore has its own sequence number space, its own
+// SeqNoAllocator file and its own scanning state. The default namespace,
+// NamespaceReceive, is the public rand root seed itself, so its public rands
+// are exactly the AIP11 ones. The PRF input of a namespace never has the
+// 8-byte form of EncodeSeqNo, so sub-roots never coincide with public rands.
+
+// Errors
+
+var (
+	ErrPublicRandNamespaceInvalid = errors.New("public rand namespace is invalid")
+)
+
+const publicRandNamespaceLabel = "PublicRandNamespace"
+
+// PublicRandNamespace names an independent sequence number space of an account.
+// Names follow the rules of sub-master path components.
+type PublicRandNamespace string
+
+const (
+	// NamespaceReceive is the default namespace, used for invoices.
+	NamespaceReceive PublicRandNamespace = ""
+	// NamespaceChange is used for change outputs.
+	NamespaceChange PublicRandNamespace = "change"
+	// NamespaceInternal is used for transfers between accounts of the wallet.
+	NamespaceInternal PublicRandNamespace = "internal"
+)
+
+// Valid reports whether the namespace is the default namespace or a well-formed name.
+func (ns PublicRandNamespace) Valid() bool {
+	return ns == NamespaceReceive || validSubMasterPathComponent(string(ns))
+}
+
+// DerivePublicRandNamespaceRootSeed derives the root seed of the namespace
+// from the public rand root seed. For NamespaceReceive it returns a copy of
+// the public rand root seed.
+func DerivePublicRandNamespaceRootSeed(publicRandRootSeed []byte, ns PublicRandNamespace) ([]byte, error) {
+	root, err := softwareKeyBytes(defaultDeriver.DerivePublicRandNamespaceRootSeed(SoftwareKey(publicRandRootSeed), ns))
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(root), nil
+}
+
+// MasterSeedToNamespacePublicRandRootSeed derives the root seed of the
+// namespace from the master seed.
+func MasterSeedToNamespacePublicRandRootSeed(masterSeed []byte, ns PublicRandNamespace) ([]byte, error) {
+	publicRandRootSeed, err := MasterSeedToAccountPublicRandRootSeed(masterSeed)
+	if err != nil {
+		return nil, err
+	}
+	return DerivePublicRandNamespaceRootSeed(publicRandRootSeed, ns)
+}
+
+// ScanNamespaces runs ScanPublicRands independently in each namespace and
+// returns the result per namespace.
+func ScanNamespaces(ctx context.Context, publicRandRootSeed []byte, namespaces []PublicRandNamespace, oracle UsageOracle, gapLimit uint32) (map[PublicRandNamespace]ScanResult, error) {
+	results := make(map[PublicRandNamespace]ScanResult, len(namespaces))
+	for _, ns := range namespaces {
+		root, err := DerivePublicRandNamespaceRootSeed(publicRandRootSeed, ns)
+		if err != nil {
+			return nil, err
+		}
+		result, err := ScanPublicRands(ctx, root, oracle, gapLimit)
+		if err != nil {
+			return nil, err
+		}
+		results[ns] = result
+	}
+	return results, nil
+}
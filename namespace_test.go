@@ -0,0 +1,78 @@
+package aip11_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleDerivePublicRandNamespaceRootSeed() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	publicRandRootSeed, _ := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+
+	changeRootSeed, _ := aip11.DerivePublicRandNamespaceRootSeed(publicRandRootSeed, aip11.NamespaceChange)
+	changeRand, _ := aip11.DerivePublicRand(changeRootSeed, 0)
+	fmt.Println(len(changeRand))
+	// Output: 64
+}
+
+func TestDerivePublicRandNamespaceRootSeed(t *testing.T) {
+	vectors := getAIP11Vector()
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			masterSeed, err := hex.DecodeString(v.masterSeed)
+			assert.NoError(t, err, "Master seed should be decoded correctly")
+
+			receiveRootSeed, err := aip11.MasterSeedToNamespacePublicRandRootSeed(masterSeed, aip11.NamespaceReceive)
+			assert.NoError(t, err, "Namespace root seed should be derived correctly")
+			assert.Equal(t, v.publicRandRootSeed, hex.EncodeToString(receiveRootSeed), "Default namespace should be the AIP11 public rand root seed")
+			for _, publicRand := range v.publicRands {
+				result, err := aip11.DerivePublicRand(receiveRootSeed, publicRand.seqNo)
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				assert.Equal(t, publicRand.expected, hex.EncodeToString(result), "Default namespace public rand should be the same")
+			}
+
+			changeRootSeed, err := aip11.MasterSeedToNamespacePublicRandRootSeed(masterSeed, aip11.NamespaceChange)
+			assert.NoError(t, err, "Namespace root seed should be derived correctly")
+			internalRootSeed, err := aip11.MasterSeedToNamespacePublicRandRootSeed(masterSeed, aip11.NamespaceInternal)
+			assert.NoError(t, err, "Namespace root seed should be derived correctly")
+			assert.NotEqual(t, receiveRootSeed, changeRootSeed, "Change namespace should be independent")
+			assert.NotEqual(t, receiveRootSeed, internalRootSeed, "Internal namespace should be independent")
+			assert.NotEqual(t, changeRootSeed, internalRootSeed, "Namespaces should be independent")
+		})
+	}
+
+	publicRandRootSeed := make([]byte, 64)
+	_, err := aip11.DerivePublicRandNamespaceRootSeed(publicRandRootSeed, "not valid")
+	assert.ErrorIs(t, err, aip11.ErrPublicRandNamespaceInvalid, "Invalid namespace should be rejected")
+	_, err = aip11.DerivePublicRandNamespaceRootSeed(publicRandRootSeed[:32], aip11.NamespaceChange)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandRootSeedInvalid, "Invalid public rand root seed should be rejected")
+}
+
+func TestScanNamespaces(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	changeRootSeed, err := aip11.DerivePublicRandNamespaceRootSeed(publicRandRootSeed, aip11.NamespaceChange)
+	assert.NoError(t, err, "Namespace root seed should be derived correctly")
+
+	oracle := aip11.NewMemoryUsageOracle()
+	for _, seqNo := range []uint32{0, 1, 2} {
+		publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, seqNo)
+		assert.NoError(t, err, "Public rand should be generated correctly")
+		oracle.MarkUsed(publicRand)
+	}
+	publicRand, err := aip11.DerivePublicRand(changeRootSeed, 0)
+	assert.NoError(t, err, "Public rand should be generated correctly")
+	oracle.MarkUsed(publicRand)
+
+	namespaces := []aip11.PublicRandNamespace{aip11.NamespaceReceive, aip11.NamespaceChange, aip11.NamespaceInternal}
+	results, err := aip11.ScanNamespaces(context.Background(), publicRandRootSeed, namespaces, oracle, 5)
+	assert.NoError(t, err, "Namespaces should be scanned correctly")
+	assert.Equal(t, aip11.ScanResult{Found: true, HighestUsed: 2, Scanned: 8}, results[aip11.NamespaceReceive], "Receive namespace should be scanned independently")
+	assert.Equal(t, aip11.ScanResult{Found: true, HighestUsed: 0, Scanned: 6}, results[aip11.NamespaceChange], "Change namespace should be scanned independently")
+	assert.Equal(t, aip11.ScanResult{Scanned: 5}, results[aip11.NamespaceInternal], "Internal namespace should be scanned independently")
+}
@@ -0,0 +1,108 @@
+package aip11
+
+import (
+	"errors"
+	"fmt"
+)
+
+// This file provides network separation of the derivation.
+//
+// On Mainnet the derivation is exactly AIP11. On every other network the
+// master seed is derived as
+//
+//	PRF(entropySeed, "NetworkAccountMasterSeed" || encode_string(network) || customizationContext)
+//
+// Every AIP11 PRF input starts with "AccountMasterSeed", so no non-mainnet
+// master seed can coincide with a mainnet one. All root seeds and public rands
+// derived from the master seed inherit the separation.
+
+// Errors
+
+var (
+	ErrNetworkUnknown  = errors.New("unknown network")
+	ErrNetworkMismatch = errors.New("network does not match")
+	ErrNetworkTagged   = errors.New("network-tagged data is malformed")
+)
+
+// Network identifies the chain a derivation belongs to. The numeric value is
+// the network tag carried by serialized artifacts.
+type Network byte
+
+const (
+	Mainnet Network = 0x00
+	Testnet Network = 0x01
+	Regtest Network = 0x02
+)
+
+const networkMasterSeedLabel = "NetworkAccountMasterSeed"
+
+// String returns the name of the network.
+func (n Network) String() string {
+	switch n {
+	case Mainnet:
+		return "mainnet"
+	case Testnet:
+		return "testnet"
+	case Regtest:
+		return "regtest"
+	default:
+		return fmt.Sprintf("network(%d)", byte(n))
+	}
+}
+
+// Valid reports whether n is a known network.
+func (n Network) Valid() bool {
+	return n == Mainnet || n == Testnet || n == Regtest
+}
+
+// ParseNetwork parses the name of a network as returned by Network.String.
+func ParseNetwork(s string) (Network, error) {
+	switch s {
+	case "mainnet":
+		return Mainnet, nil
+	case "testnet":
+		return Testnet, nil
+	case "regtest":
+		return Regtest, nil
+	default:
+		return 0, ErrNetworkUnknown
+	}
+}
+
+// CheckNetwork returns ErrNetworkMismatch if actual is not expected.
+func CheckNetwork(expected, actual Network) error {
+	if !actual.Valid() {
+		return ErrNetworkUnknown
+	}
+	if expected != actual {
+		return fmt.Errorf("%w: expected %s, got %s", ErrNetworkMismatch, expected, actual)
+	}
+	return nil
+}
+
+// EntropySeedToNetworkMasterSeed derives the master seed of the given network
+// from the entropy seed. For Mainnet it equals EntropySeedToMasterSeed.
+func EntropySeedToNetworkMasterSeed(entropySeed []byte, customizationContext []byte, network Network) ([]byte, error) {
+	return softwareKeyBytes(defaultDeriver.EntropySeedToNetworkMasterSeed(SoftwareKey(entropySeed), customizationContext, network))
+}
+
+// MarshalNetworkTagged prefixes data with the network tag.
+func MarshalNetworkTagged(network Network, data []byte) []byte {
+	tagged := make([]byte, 0, 1+len(data))
+	tagged = append(tagged, byte(network))
+	return append(tagged, data...)
+}
+
+// UnmarshalNetworkTagged checks the network tag of data produced by
+// MarshalNetworkTagged against the expected network and returns the payload.
+func UnmarshalNetworkTagged(tagged []byte, expected Network) ([]byte, error) {
+	if len(tagged) < 1 {
+		return nil, ErrNetworkTagged
+	}
+	if err := CheckNetwork(expected, Network(tagged[0])); err != nil {
+		return nil, err
+	}
+	data := make([]byte, len(tagged)-1)
+	copy(data, tagged[1:])
+	return data, nil
+}
@@ -0,0 +1,71 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleEntropySeedToNetworkMasterSeed() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	mainnet, _ := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Mainnet)
+	testnet, _ := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Testnet)
+	fmt.Println(len(testnet), string(mainnet) == string(testnet))
+	// Output: 64 false
+}
+
+func TestEntropySeedToNetworkMasterSeed(t *testing.T) {
+	vectors := getAIP11Vector()
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			entropySeed, err := hex.DecodeString(v.entropySeed)
+			assert.NoError(t, err, "Entropy seed should be decoded correctly")
+
+			mainnet, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Mainnet)
+			assert.NoError(t, err, "Mainnet master seed should be generated correctly")
+			assert.Equal(t, v.masterSeed, hex.EncodeToString(mainnet), "Mainnet master seed should be the AIP11 master seed")
+
+			testnet, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Testnet)
+			assert.NoError(t, err, "Testnet master seed should be generated correctly")
+			regtest, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Regtest)
+			assert.NoError(t, err, "Regtest master seed should be generated correctly")
+			assert.NotEqual(t, mainnet, testnet, "Testnet master seed should differ from mainnet")
+			assert.NotEqual(t, mainnet, regtest, "Regtest master seed should differ from mainnet")
+			assert.NotEqual(t, testnet, regtest, "Regtest master seed should differ from testnet")
+		})
+	}
+
+	entropySeed, _ := aip11.SampleEntropySeed()
+	_, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Network(9))
+	assert.ErrorIs(t, err, aip11.ErrNetworkUnknown, "Unknown network should be rejected")
+	_, err = aip11.EntropySeedToNetworkMasterSeed(entropySeed[:16], []byte{}, aip11.Testnet)
+	assert.ErrorIs(t, err, aip11.ErrEntropySeedInvalid, "Invalid entropy seed should be rejected")
+}
+
+func TestParseNetwork(t *testing.T) {
+	for _, network := range []aip11.Network{aip11.Mainnet, aip11.Testnet, aip11.Regtest} {
+		parsed, err := aip11.ParseNetwork(network.String())
+		assert.NoError(t, err, "Network should be parsed correctly")
+		assert.Equal(t, network, parsed, "Parsed network should be the same")
+	}
+	_, err := aip11.ParseNetwork("signet")
+	assert.ErrorIs(t, err, aip11.ErrNetworkUnknown, "Unknown network should be rejected")
+}
+
+func TestNetworkTagged(t *testing.T) {
+	data := []byte{1, 2, 3}
+	tagged := aip11.MarshalNetworkTagged(aip11.Testnet, data)
+
+	result, err := aip11.UnmarshalNetworkTagged(tagged, aip11.Testnet)
+	assert.NoError(t, err, "Network tagged data should be decoded correctly")
+	assert.Equal(t, data, result, "Payload should be the same")
+
+	_, err = aip11.UnmarshalNetworkTagged(tagged, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrNetworkMismatch, "Wrong network should be rejected")
+
+	_, err = aip11.UnmarshalNetworkTagged([]byte{}, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrNetworkTagged, "Empty data should be rejected")
+}
@@ -0,0 +1,49 @@
+package aip11
+
+import (
+	"golang.org/x/text/unicode/norm"
+)
+
+// This file provides BIP-0039-style passphrase protection ("25th word").
+//
+// The passphrase is NFKD-normalized and, optionally, stretched with a
+// memory-hard KDF. The result is carried in a structured customizationContext,
+// so every passphrase yields an independent account:
+//
+//	no stretching:   ContextBuilder.String("Passphrase").String("none").Bytes(NFKD(passphrase))
+//	with stretching: ContextBuilder.String("Passphrase").String(params.String()).Bytes(KDF(NFKD(passphrase), salt))
+//
+// where salt = the first 32 bytes of PRF(entropySeed, "PassphraseSalt"). The
+// empty passphrase always yields the AIP11 default account.
+
+const (
+	passphraseLabel     = "Passphrase"
+	passphraseSaltLabel = "PassphraseSalt"
+	passphraseKeyLength = 64
+)
+
+// NormalizePassphrase returns the NFKD normalization of the passphrase in UTF-8.
+func NormalizePassphrase(passphrase string) []byte {
+	return norm.NFKD.Bytes([]byte(passphrase))
+}
+
+// PassphraseContext returns the customizationContext for the passphrase.
+// params must be recorded alongside the wallet to reproduce the derivation.
+//
+// The empty passphrase yields the empty customizationContext, i.e. the AIP11
+// default account, whatever KDF params names: params is validated but not
+// applied, so recording stretching parameters for a wallet without passphrase
+// never moves it away from its default account.
+func PassphraseContext(entropySeed []byte, passphrase string, params KDFParams) ([]byte, error) {
+	return defaultDeriver.PassphraseContext(SoftwareKey(entropySeed), passphrase, params)
+}
+
+// EntropySeedToMasterSeedWithPassphrase derives the master seed of the account
+// protected by the passphrase.
+func EntropySeedToMasterSeedWithPassphrase(entropySeed []byte, passphrase string, params KDFParams) ([]byte, error) {
+	context, err := PassphraseContext(entropySeed, passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	return EntropySeedToMasterSeed(entropySeed, context)
+}
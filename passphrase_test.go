@@ -0,0 +1,87 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleEntropySeedToMasterSeedWithPassphrase() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	params := aip11.KDFParams{Algorithm: aip11.KDFArgon2id, Time: 1, Memory: 64, Threads: 1}
+	masterSeed, _ := aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, "correct horse battery staple", params)
+	fmt.Println(len(masterSeed), params)
+	// Output: 64 argon2id:t=1,m=64,p=1
+}
+
+func TestEntropySeedToMasterSeedWithPassphrase(t *testing.T) {
+	vectors := getAIP11Vector()
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			entropySeed, err := hex.DecodeString(v.entropySeed)
+			assert.NoError(t, err, "Entropy seed should be decoded correctly")
+
+			masterSeed, err := aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, "", aip11.KDFParams{})
+			assert.NoError(t, err, "Master seed should be generated correctly")
+			assert.Equal(t, v.masterSeed, hex.EncodeToString(masterSeed), "Empty passphrase should give the AIP11 master seed")
+
+			for _, params := range []aip11.KDFParams{aip11.DefaultArgon2idParams(), aip11.DefaultScryptParams()} {
+				masterSeed, err = aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, "", params)
+				assert.NoError(t, err, "Master seed should be generated correctly")
+				assert.Equal(t, v.masterSeed, hex.EncodeToString(masterSeed), "Empty passphrase should not be stretched")
+			}
+			_, err = aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, "", aip11.KDFParams{Algorithm: aip11.KDFScrypt})
+			assert.ErrorIs(t, err, aip11.ErrKDFParamsInvalid, "Invalid KDF params should be rejected with an empty passphrase")
+		})
+	}
+}
+
+func TestPassphraseIndependentAccounts(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+
+	argon2id := aip11.KDFParams{Algorithm: aip11.KDFArgon2id, Time: 1, Memory: 64, Threads: 1}
+	scrypt := aip11.KDFParams{Algorithm: aip11.KDFScrypt, LogN: 4, R: 8, P: 1}
+	testCases := []struct {
+		passphrase string
+		params     aip11.KDFParams
+	}{
+		{passphrase: "", params: aip11.KDFParams{}},
+		{passphrase: "decoy", params: aip11.KDFParams{}},
+		{passphrase: "real", params: aip11.KDFParams{}},
+		{passphrase: "real", params: argon2id},
+		{passphrase: "real", params: scrypt},
+	}
+
+	seen := map[string]int{}
+	for i, tc := range testCases {
+		masterSeed, err := aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, tc.passphrase, tc.params)
+		assert.NoError(t, err, "Master seed should be generated correctly")
+		j, ok := seen[string(masterSeed)]
+		assert.False(t, ok, "Account %d should be independent of account %d", i, j)
+		seen[string(masterSeed)] = i
+
+		again, err := aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, tc.passphrase, tc.params)
+		assert.NoError(t, err, "Master seed should be generated correctly")
+		assert.Equal(t, masterSeed, again, "Derivation should be reproducible")
+	}
+}
+
+func TestPassphraseNormalization(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+
+	// "é" as a single code point and as "e" followed by a combining acute accent.
+	composed, err := aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, "caf\u00e9", aip11.KDFParams{})
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	decomposed, err := aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, "cafe\u0301", aip11.KDFParams{})
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	assert.Equal(t, composed, decomposed, "Passphrase should be NFKD-normalized")
+	assert.Equal(t, []byte("cafe\u0301"), aip11.NormalizePassphrase("caf\u00e9"), "Passphrase should be NFKD-normalized")
+
+	_, err = aip11.EntropySeedToMasterSeedWithPassphrase(entropySeed, "real", aip11.KDFParams{Algorithm: aip11.KDFScrypt})
+	assert.ErrorIs(t, err, aip11.ErrKDFParamsInvalid, "Invalid KDF params should be rejected")
+}
@@ -0,0 +1,329 @@
+package aip11
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// This file provides a human-readable derivation path language, e.g.
+//
+//	aip11:ctx=treasury/acct=3/rootseeds/publicrand/17
+//
+// A path is "aip11:" followed by '/'-separated segments. Option segments come
+// first, in any order:
+//
+//	net=<network>   network, see EntropySeedToNetworkMasterSeed (default mainnet);
+//	                from a master seed, the network it belongs to, which selects
+//	                the available root seed kinds
+//	ctx=<context>   customizationContext, as text or as 0x-prefixed hex
+//	acct=<n>        account index, see AccountN
+//	sub=<component> sub-master step, see DeriveSubMasterSeed (repeatable, in order)
+//
+// and a target follows:
+//
+//	master                        the master seed
+//	rootseeds                     the account root seeds, as MasterSeedToAccountRootSeeds
+//	rootseeds/<kind>              a single root seed, <kind> one of spend, serialnumber,
+//	                              detector, vk, vkaut, publicrand and the registered
+//	                              kinds, see RegisterRootSeedKind
+//	rootseeds/publicrand/<seqNo>  the public rand with the given sequence number
+//
+// The customizationContext is the ctx value when only ctx is given, and
+// AccountIndexContext(n) when only acct is given. When both are given it is
+// NewContextBuilder().Bytes(ctx).Uint(n).Build().
+
+// Errors
+
+var (
+	ErrDerivationPathInvalid          = errors.New("derivation path is invalid")
+	ErrDerivationPathNeedsEntropySeed = errors.New("derivation path has options that require the entropy seed")
+)
+
+const derivationPathScheme = "aip11:"
+
+// DerivationTarget is the kind of value a derivation path resolves to.
+type DerivationTarget int
+
+const (
+	TargetMasterSeed DerivationTarget = iota
+	TargetAccountRootSeeds
+	TargetRootSeed
+	TargetPublicRand
+)
+
+// Names of the built-in root seed kinds, in the order of
+// MasterSeedToAccountRootSeeds. A derivation path can also select registered
+// kinds by name, see RegisterRootSeedKind.
+const (
+	RootSeedSpend        = "spend"
+	RootSeedSerialNumber = "serialnumber"
+	RootSeedDetector     = "detector"
+	RootSeedVK           = "vk"
+	RootSeedVKAut        = "vkaut"
+	RootSeedPublicRand   = "publicrand"
+)
+
+// DerivationPath is a parsed derivation path.
+type DerivationPath struct {
+	Network    Network
+	Context    []byte
+	HasContext bool
+	Account    uint32
+	HasAccount bool
+	SubMaster  SubMasterPath
+
+	Target   DerivationTarget
+	RootSeed string // for TargetRootSeed
+	SeqNo    uint32 // for TargetPublicRand
+}
+
+// DerivationResult is the value a derivation path resolves to. For
+// TargetAccountRootSeeds the root seeds are in AccountRootSeeds, otherwise the
+// value is in Value.
+type DerivationResult struct {
+	Target           DerivationTarget
+	Value            []byte
+	AccountRootSeeds [][]byte
+}
+
+// ParseDerivationPath parses a derivation path.
+func ParseDerivationPath(s string) (*DerivationPath, error) {
+	rest, ok := strings.CutPrefix(s, derivationPathScheme)
+	if !ok || rest == "" {
+		return nil, ErrDerivationPathInvalid
+	}
+	segments := strings.Split(rest, "/")
+
+	p := &DerivationPath{Network: Mainnet, SubMaster: SubMasterPath{}}
+	hasNetwork := false
+	i := 0
+	for ; i < len(segments); i++ {
+		key, value, ok := strings.Cut(segments[i], "=")
+		if !ok {
+			break
+		}
+		switch {
+		case key == "net" && !hasNetwork:
+			network, err := ParseNetwork(value)
+			if err != nil {
+				return nil, ErrDerivationPathInvalid
+			}
+			p.Network, hasNetwork = network, true
+		case key == "ctx" && !p.HasContext:
+			context, err := parsePathContext(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Context, p.HasContext = context, true
+		case key == "acct" && !p.HasAccount:
+			n, err := parsePathUint32(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Account, p.HasAccount = n, true
+		case key == "sub":
+			if !validSubMasterPathComponent(value) {
+				return nil, ErrDerivationPathInvalid
+			}
+			p.SubMaster = append(p.SubMaster, value)
+		default:
+			return nil, ErrDerivationPathInvalid
+		}
+	}
+
+	target := segments[i:]
+	switch {
+	case len(target) == 1 && target[0] == "master":
+		p.Target = TargetMasterSeed
+	case len(target) == 1 && target[0] == "rootseeds":
+		p.Target = TargetAccountRootSeeds
+	case len(target) == 2 && target[0] == "rootseeds" && validRootSeedName(target[1]):
+		p.Target = TargetRootSeed
+		p.RootSeed = target[1]
+	case len(target) == 3 && target[0] == "rootseeds" && target[1] == RootSeedPublicRand:
+		seqNo, err := parsePathUint32(target[2])
+		if err != nil {
+			return nil, err
+		}
+		p.Target = TargetPublicRand
+		p.RootSeed = RootSeedPublicRand
+		p.SeqNo = seqNo
+	default:
+		return nil, ErrDerivationPathInvalid
+	}
+	return p, nil
+}
+
+// String returns the canonical form of the derivation path.
+func (p *DerivationPath) String() string {
+	segments := []string{}
+	if p.Network != Mainnet {
+		segments = append(segments, "net="+p.Network.String())
+	}
+	if p.HasContext {
+		segments = append(segments, "ctx="+formatPathContext(p.Context))
+	}
+	if p.HasAccount {
+		segments = append(segments, "acct="+strconv.FormatUint(uint64(p.Account), 10))
+	}
+	for _, component := range p.SubMaster {
+		segments = append(segments, "sub="+component)
+	}
+	switch p.Target {
+	case TargetMasterSeed:
+		segments = append(segments, "master")
+	case TargetAccountRootSeeds:
+		segments = append(segments, "rootseeds")
+	case TargetRootSeed:
+		segments = append(segments, "rootseeds", p.RootSeed)
+	case TargetPublicRand:
+		segments = append(segments, "rootseeds", RootSeedPublicRand, strconv.FormatUint(uint64(p.SeqNo), 10))
+	}
+	return derivationPathScheme + strings.Join(segments, "/")
+}
+
+// CustomizationContext returns the customizationContext selected by the ctx
+// and acct options.
+func (p *DerivationPath) CustomizationContext() []byte {
+	switch {
+	case p.HasContext && p.HasAccount:
+		return NewContextBuilder().Bytes(p.Context).Uint(uint64(p.Account)).Build()
+	case p.HasAccount:
+		return AccountIndexContext(p.Account)
+	case p.HasContext:
+		return p.Context
+	default:
+		return []byte{}
+	}
+}
+
+// Resolve evaluates the derivation path against the entropy seed.
+func (p *DerivationPath) Resolve(entropySeed []byte) (*DerivationResult, error) {
+	masterSeed, err := EntropySeedToNetworkMasterSeed(entropySeed, p.CustomizationContext(), p.Network)
+	if err != nil {
+		return nil, err
+	}
+	return p.resolveMasterSeed(masterSeed)
+}
+
+// ResolveFromMasterSeed evaluates the derivation path against the master seed
+// of the path's network, so that registered kinds resolve from a testnet or
+// regtest master seed. The path must not have ctx or acct options, which apply
+// to the entropy seed.
+func (p *DerivationPath) ResolveFromMasterSeed(masterSeed []byte) (*DerivationResult, error) {
+	if p.HasContext || p.HasAccount {
+		return nil, ErrDerivationPathNeedsEntropySeed
+	}
+	return p.resolveMasterSeed(masterSeed)
+}
+
+func (p *DerivationPath) resolveMasterSeed(masterSeed []byte) (*DerivationResult, error) {
+	masterSeed, err := DeriveSubMasterSeed(masterSeed, p.SubMaster)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DerivationResult{Target: p.Target}
+	switch p.Target {
+	case TargetMasterSeed:
+		result.Value = masterSeed
+	case TargetAccountRootSeeds:
+		result.AccountRootSeeds, err = MasterSeedToAccountRootSeeds(masterSeed)
+	case TargetRootSeed:
+		if p.RootSeed == RootSeedPublicRand {
+			result.Value, err = MasterSeedToAccountPublicRandRootSeed(masterSeed)
+			break
+		}
+		result.Value, err = MasterSeedToRootSeed(masterSeed, p.RootSeed, p.Network)
+	case TargetPublicRand:
+		var publicRandRootSeed []byte
+		publicRandRootSeed, err = MasterSeedToAccountPublicRandRootSeed(masterSeed)
+		if err == nil {
+			result.Value, err = DerivePublicRand(publicRandRootSeed, p.SeqNo)
+		}
+	default:
+		return nil, ErrDerivationPathInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResolveDerivationPath parses the derivation path and evaluates it against
+// the entropy seed.
+func ResolveDerivationPath(path string, entropySeed []byte) (*DerivationResult, error) {
+	p, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Resolve(entropySeed)
+}
+
+// ResolveDerivationPathFromMasterSeed parses the derivation path and evaluates
+// it against the master seed.
+func ResolveDerivationPathFromMasterSeed(path string, masterSeed []byte) (*DerivationResult, error) {
+	p, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.ResolveFromMasterSeed(masterSeed)
+}
+
+func validRootSeedName(name string) bool {
+	if name == RootSeedPublicRand {
+		return true
+	}
+	_, ok := LookupRootSeedKind(name)
+	return ok
+}
+
+func parsePathUint32(s string) (uint32, error) {
+	// Reject signs and leading zeros so that every number has one spelling.
+	if s == "" || s[0] < '0' || s[0] > '9' || (len(s) > 1 && s[0] == '0') {
+		return 0, ErrDerivationPathInvalid
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, ErrDerivationPathInvalid
+	}
+	return uint32(n), nil
+}
+
+func parsePathContext(s string) ([]byte, error) {
+	if h, ok := strings.CutPrefix(s, "0x"); ok {
+		context, err := hex.DecodeString(h)
+		if err != nil || h != hex.EncodeToString(context) {
+			return nil, ErrDerivationPathInvalid
+		}
+		return context, nil
+	}
+	if !validPathContextText(s) {
+		return nil, ErrDerivationPathInvalid
+	}
+	return []byte(s), nil
+}
+
+func formatPathContext(context []byte) string {
+	if validPathContextText(string(context)) && !strings.HasPrefix(string(context), "0x") {
+		return string(context)
+	}
+	return "0x" + hex.EncodeToString(context)
+}
+
+func validPathContextText(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.' || c == '_' || c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleResolveDerivationPath() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	result, _ := aip11.ResolveDerivationPath("aip11:ctx=treasury/acct=3/rootseeds/publicrand/17", entropySeed)
+	fmt.Println(result.Target == aip11.TargetPublicRand, len(result.Value))
+	// Output: true 64
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "aip11:master", expected: "aip11:master"},
+		{input: "aip11:net=mainnet/rootseeds", expected: "aip11:rootseeds"},
+		{input: "aip11:acct=3/ctx=treasury/rootseeds/vkaut", expected: "aip11:ctx=treasury/acct=3/rootseeds/vkaut"},
+		{input: "aip11:net=testnet/ctx=0x00ff/sub=org/sub=desk-1/rootseeds/publicrand", expected: "aip11:net=testnet/ctx=0x00ff/sub=org/sub=desk-1/rootseeds/publicrand"},
+		{input: "aip11:ctx=0x/rootseeds/publicrand/4294967295", expected: "aip11:ctx=0x/rootseeds/publicrand/4294967295"},
+		{input: "aip11:ctx=0x6162/master", expected: "aip11:ctx=ab/master"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			p, err := aip11.ParseDerivationPath(tc.input)
+			assert.NoError(t, err, "Derivation path should be parsed correctly")
+			assert.Equal(t, tc.expected, p.String(), "Derivation path should be formatted canonically")
+		})
+	}
+
+	invalid := []string{
+		"",
+		"aip11:",
+		"bip32:master",
+		"aip11:rootseeds/unknown",
+		"aip11:rootseeds/spend/1",
+		"aip11:rootseeds/publicrand/4294967296",
+		"aip11:rootseeds/publicrand/007",
+		"aip11:rootseeds/publicrand/+7",
+		"aip11:acct=1/acct=2/master",
+		"aip11:net=signet/master",
+		"aip11:ctx=a b/master",
+		"aip11:ctx=0xABCD/master",
+		"aip11:sub=/master",
+		"aip11:master/ctx=treasury",
+		"aip11:foo=bar/master",
+	}
+	for _, input := range invalid {
+		t.Run(input, func(t *testing.T) {
+			_, err := aip11.ParseDerivationPath(input)
+			assert.ErrorIs(t, err, aip11.ErrDerivationPathInvalid, "Invalid derivation path should be rejected")
+		})
+	}
+}
+
+func TestResolveDerivationPath(t *testing.T) {
+	vectors := getAIP11Vector()
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			entropySeed, err := hex.DecodeString(v.entropySeed)
+			assert.NoError(t, err, "Entropy seed should be decoded correctly")
+
+			result, err := aip11.ResolveDerivationPath("aip11:master", entropySeed)
+			assert.NoError(t, err, "Derivation path should be resolved correctly")
+			assert.Equal(t, v.masterSeed, hex.EncodeToString(result.Value), "Master seed should be the same")
+
+			result, err = aip11.ResolveDerivationPath("aip11:rootseeds", entropySeed)
+			assert.NoError(t, err, "Derivation path should be resolved correctly")
+			assert.Equal(t, aip11.TargetAccountRootSeeds, result.Target, "Target should be the account root seeds")
+			assert.Equal(t, v.rootSeeds.coinSpKeyRootSeed, hex.EncodeToString(result.AccountRootSeeds[0]), "Coin SP key root seed should be the same")
+
+			expected := map[string]string{
+				"spend":        v.rootSeeds.coinSpKeyRootSeed,
+				"serialnumber": v.rootSeeds.coinSnKeyRootSeed,
+				"detector":     v.rootSeeds.coinDetectorRootKey,
+				"vk":           v.rootSeeds.coinVKRootSeed,
+				"vkaut":        v.rootSeeds.coinVKeyRootSeedAut,
+				"publicrand":   v.publicRandRootSeed,
+			}
+			for name, seed := range expected {
+				result, err = aip11.ResolveDerivationPath("aip11:rootseeds/"+name, entropySeed)
+				assert.NoError(t, err, "Derivation path should be resolved correctly")
+				assert.Equal(t, seed, hex.EncodeToString(result.Value), "Root seed %s should be the same", name)
+			}
+
+			for _, publicRand := range v.publicRands {
+				result, err = aip11.ResolveDerivationPath(fmt.Sprintf("aip11:rootseeds/publicrand/%d", publicRand.seqNo), entropySeed)
+				assert.NoError(t, err, "Derivation path should be resolved correctly")
+				assert.Equal(t, publicRand.expected, hex.EncodeToString(result.Value), "Public rand should be the same")
+			}
+		})
+	}
+}
+
+func TestResolveDerivationPathOptions(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+
+	account3, err := aip11.AccountN(entropySeed, 3)
+	assert.NoError(t, err, "Account should be derived correctly")
+	result, err := aip11.ResolveDerivationPath("aip11:acct=3/master", entropySeed)
+	assert.NoError(t, err, "Derivation path should be resolved correctly")
+	assert.Equal(t, account3, result.Value, "acct should select AccountN")
+
+	treasury, err := aip11.EntropySeedToMasterSeed(entropySeed, []byte("treasury"))
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	result, err = aip11.ResolveDerivationPath("aip11:ctx=treasury/master", entropySeed)
+	assert.NoError(t, err, "Derivation path should be resolved correctly")
+	assert.Equal(t, treasury, result.Value, "ctx should select the raw customization context")
+
+	context := aip11.NewContextBuilder().Bytes([]byte("treasury")).Uint(3).Build()
+	masterSeed, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, context, aip11.Testnet)
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	deskSeed, err := aip11.DeriveSubMasterSeed(masterSeed, aip11.SubMasterPath{"org", "desk-1"})
+	assert.NoError(t, err, "Sub-master seed should be derived correctly")
+	publicRandRootSeed, err := aip11.MasterSeedToAccountPublicRandRootSeed(deskSeed)
+	assert.NoError(t, err, "Public rand root seed should be generated correctly")
+	publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, 17)
+	assert.NoError(t, err, "Public rand should be generated correctly")
+
+	result, err = aip11.ResolveDerivationPath("aip11:net=testnet/ctx=treasury/acct=3/sub=org/sub=desk-1/rootseeds/publicrand/17", entropySeed)
+	assert.NoError(t, err, "Derivation path should be resolved correctly")
+	assert.Equal(t, publicRand, result.Value, "Resolver should chain every derivation step")
+
+	result, err = aip11.ResolveDerivationPathFromMasterSeed("aip11:sub=org/sub=desk-1/rootseeds/publicrand/17", masterSeed)
+	assert.NoError(t, err, "Derivation path should be resolved correctly")
+	assert.Equal(t, publicRand, result.Value, "Resolver should accept a master seed")
+
+	_, err = aip11.ResolveDerivationPathFromMasterSeed("aip11:acct=3/master", masterSeed)
+	assert.ErrorIs(t, err, aip11.ErrDerivationPathNeedsEntropySeed, "Entropy seed options should be rejected for a master seed")
+	_, err = aip11.ResolveDerivationPathFromMasterSeed("aip11:ctx=treasury/master", masterSeed)
+	assert.ErrorIs(t, err, aip11.ErrDerivationPathNeedsEntropySeed, "Entropy seed options should be rejected for a master seed")
+	_, err = aip11.ResolveDerivationPath("aip11:master", entropySeed[:16])
+	assert.ErrorIs(t, err, aip11.ErrEntropySeedInvalid, "Invalid entropy seed should be rejected")
+}
+
+func TestResolveRegisteredRootSeedFromMasterSeed(t *testing.T) {
+	kind := aip11.RootSeedKind{Name: "path-kind", Label: "PathKindRootSeed", Experimental: true}
+	assert.NoError(t, aip11.RegisterRootSeedKind(kind), "Kind should be registered correctly")
+	defer aip11.UnregisterRootSeedKind(kind.Name)
+
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	masterSeed, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Testnet)
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	expected, err := aip11.MasterSeedToRootSeed(masterSeed, kind.Name, aip11.Testnet)
+	assert.NoError(t, err, "Root seed should be derived correctly")
+
+	result, err := aip11.ResolveDerivationPathFromMasterSeed("aip11:net=testnet/rootseeds/path-kind", masterSeed)
+	assert.NoError(t, err, "Registered kind should be resolved from a testnet master seed")
+	assert.Equal(t, expected, result.Value, "Registered root seed should be the same")
+	result, err = aip11.ResolveDerivationPath("aip11:net=testnet/rootseeds/path-kind", entropySeed)
+	assert.NoError(t, err, "Registered kind should be resolved from the entropy seed")
+	assert.Equal(t, expected, result.Value, "Registered root seed should be the same")
+
+	_, err = aip11.ResolveDerivationPathFromMasterSeed("aip11:rootseeds/path-kind", masterSeed)
+	assert.ErrorIs(t, err, aip11.ErrRootSeedKindExperimental, "Registered kind should not be resolved on mainnet")
+}
@@ -0,0 +1,334 @@
+// Package pkcs11prf provides a PKCS#11 PRF backend for aip11, so that entropy
+// seeds, master seeds and root seeds can live in an HSM.
+//
+// Seeds are stored as CKK_GENERIC_SECRET objects. PKCS#11 defines no KMAC
+// mechanism, so evaluating PRF inside the token requires a vendor-defined
+// mechanism, configured as Config.KMACMechanism, which must compute
+//
+//	KMAC256(key, data, 512, "ABELIANPRF")
+//
+// with the data as the message of C_Sign, and, for C_DeriveKey, with the data
+// as the mechanism parameter, producing a 64-byte CKK_GENERIC_SECRET. With such
+// a mechanism all seeds are sensitive, non-extractable objects and only public
+// rands leave the token.
+//
+// Tokens without it, such as SoftHSM2, are rejected by Open with
+// ErrKMACUnsupported, unless Config.AllowSoftwareFallback is set. In software
+// fallback mode seeds are still stored in the token, but as extractable,
+// non-sensitive objects whose values are read to evaluate PRF in software.
+// InToken reports which mode a Backend is in.
+package pkcs11prf
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"sync"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+
+	"github.com/miekg/pkcs11"
+)
+
+// Errors
+
+var (
+	ErrModuleLoad         = errors.New("failed to load PKCS#11 module")
+	ErrTokenNotFound      = errors.New("PKCS#11 token not found")
+	ErrKeyNotFound        = errors.New("PKCS#11 key not found")
+	ErrKMACUnsupported    = errors.New("PKCS#11 token does not support the KMAC mechanism")
+	ErrBackendClosed      = errors.New("PKCS#11 backend is closed")
+	ErrAttributeMalformed = errors.New("PKCS#11 attribute is malformed")
+)
+
+const prfOutputSize = aip11.PRFOutputSize
+
+// Config configures a Backend.
+type Config struct {
+	// Module is the path of the PKCS#11 library, e.g.
+	// /usr/lib/softhsm/libsofthsm2.so.
+	Module string
+	// TokenLabel is the label of the token to use.
+	TokenLabel string
+	// PIN is the user PIN of the token.
+	PIN string
+	// KMACMechanism is the vendor-defined mechanism evaluating PRF inside the
+	// token, or 0 if the token has none.
+	KMACMechanism uint
+	// AllowSoftwareFallback lets Open succeed on tokens without KMACMechanism,
+	// evaluating PRF in software on extractable keys.
+	AllowSoftwareFallback bool
+}
+
+// Backend is an aip11.PRFBackend whose keys are PKCS#11 objects. It is safe
+// for concurrent use.
+type Backend struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	config  Config
+	inToken bool
+	closed  bool
+}
+
+// Key is an aip11.PRFKey held by a Backend.
+type Key struct {
+	backend *Backend
+	handle  pkcs11.ObjectHandle
+	length  int
+}
+
+// Len returns the length of the key in bytes.
+func (k *Key) Len() int {
+	return k.length
+}
+
+// Open loads the module, opens a session on the token and logs in.
+func Open(config Config) (*Backend, error) {
+	ctx := pkcs11.New(config.Module)
+	if ctx == nil {
+		return nil, ErrModuleLoad
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+	b := &Backend{ctx: ctx, config: config}
+	if err := b.open(); err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Backend) open() error {
+	slots, err := b.ctx.GetSlotList(true)
+	if err != nil {
+		return err
+	}
+	slot, found := uint(0), false
+	for _, s := range slots {
+		info, err := b.ctx.GetTokenInfo(s)
+		if err == nil && strings.TrimRight(info.Label, " \x00") == b.config.TokenLabel {
+			slot, found = s, true
+			break
+		}
+	}
+	if !found {
+		return ErrTokenNotFound
+	}
+
+	if b.config.KMACMechanism != 0 {
+		mechanisms, err := b.ctx.GetMechanismList(slot)
+		if err != nil {
+			return err
+		}
+		for _, m := range mechanisms {
+			if m.Mechanism == b.config.KMACMechanism {
+				b.inToken = true
+			}
+		}
+	}
+	if !b.inToken && !b.config.AllowSoftwareFallback {
+		return ErrKMACUnsupported
+	}
+
+	b.session, err = b.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return err
+	}
+	err = b.ctx.Login(b.session, pkcs11.CKU_USER, b.config.PIN)
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		b.ctx.CloseSession(b.session)
+		return err
+	}
+	return nil
+}
+
+// InToken reports whether PRF is evaluated inside the token. If not, the
+// backend is in software fallback mode.
+func (b *Backend) InToken() bool {
+	return b.inToken
+}
+
+// Close logs out, closes the session and unloads the module. Keys derived with
+// DeriveKey are session objects and are destroyed.
+func (b *Backend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	b.ctx.Logout(b.session)
+	err := b.ctx.CloseSession(b.session)
+	b.ctx.Finalize()
+	b.ctx.Destroy()
+	return err
+}
+
+// ImportSeed stores the seed, e.g. an entropy seed, as a token object with
+// the given label.
+func (b *Backend) ImportSeed(label string, seed []byte) (*Key, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBackendClosed
+	}
+	template := append(b.secretTemplate(true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, seed),
+	)
+	handle, err := b.ctx.CreateObject(b.session, template)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{backend: b, handle: handle, length: len(seed)}, nil
+}
+
+// FindSeed returns the token object with the given label.
+func (b *Backend) FindSeed(label string) (*Key, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBackendClosed
+	}
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := b.ctx.FindObjectsInit(b.session, template); err != nil {
+		return nil, err
+	}
+	handles, _, err := b.ctx.FindObjects(b.session, 1)
+	b.ctx.FindObjectsFinal(b.session)
+	if err != nil {
+		return nil, err
+	}
+	if len(handles) == 0 {
+		return nil, ErrKeyNotFound
+	}
+
+	attributes, err := b.ctx.GetAttributeValue(b.session, handles[0], []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	length, err := decodeULong(attributes[0].Value)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{backend: b, handle: handles[0], length: length}, nil
+}
+
+// DestroyKey removes the key from the token.
+func (b *Backend) DestroyKey(key *Key) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBackendClosed
+	}
+	return b.ctx.DestroyObject(b.session, key.handle)
+}
+
+// PRF returns PRF(key, input).
+func (b *Backend) PRF(key aip11.PRFKey, input []byte) ([]byte, error) {
+	k, ok := key.(*Key)
+	if !ok || k.backend != b {
+		return nil, aip11.ErrPRFKeyUnsupported
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBackendClosed
+	}
+	return b.prf(k, input)
+}
+
+func (b *Backend) prf(k *Key, input []byte) ([]byte, error) {
+	if !b.inToken {
+		value, err := b.value(k)
+		if err != nil {
+			return nil, err
+		}
+		return aip11.PRF(value, input), nil
+	}
+
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(b.config.KMACMechanism, nil)}
+	if err := b.ctx.SignInit(b.session, mechanism, k.handle); err != nil {
+		return nil, err
+	}
+	return b.ctx.Sign(b.session, input)
+}
+
+// DeriveKey returns PRF(key, input) as a new session object.
+func (b *Backend) DeriveKey(key aip11.PRFKey, input []byte) (aip11.PRFKey, error) {
+	k, ok := key.(*Key)
+	if !ok || k.backend != b {
+		return nil, aip11.ErrPRFKeyUnsupported
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBackendClosed
+	}
+
+	var handle pkcs11.ObjectHandle
+	var err error
+	if b.inToken {
+		template := append(b.secretTemplate(false), pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, prfOutputSize))
+		mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(b.config.KMACMechanism, input)}
+		handle, err = b.ctx.DeriveKey(b.session, mechanism, k.handle, template)
+	} else {
+		var output []byte
+		output, err = b.prf(k, input)
+		if err == nil {
+			handle, err = b.ctx.CreateObject(b.session, append(b.secretTemplate(false), pkcs11.NewAttribute(pkcs11.CKA_VALUE, output)))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Key{backend: b, handle: handle, length: prfOutputSize}, nil
+}
+
+// secretTemplate returns the attributes of a seed object, stored on the token
+// if persistent and in the session otherwise.
+func (b *Backend) secretTemplate(persistent bool) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_GENERIC_SECRET),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, persistent),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_DERIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, b.inToken),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, !b.inToken),
+	}
+}
+
+// decodeULong decodes a CK_ULONG attribute value, which is in native byte
+// order.
+func decodeULong(value []byte) (int, error) {
+	switch len(value) {
+	case 4:
+		return int(binary.NativeEndian.Uint32(value)), nil
+	case 8:
+		return int(binary.NativeEndian.Uint64(value)), nil
+	default:
+		return 0, ErrAttributeMalformed
+	}
+}
+
+// value reads the value of a key in software fallback mode.
+func (b *Backend) value(k *Key) ([]byte, error) {
+	attributes, err := b.ctx.GetAttributeValue(b.session, k.handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attributes[0].Value, nil
+}
@@ -0,0 +1,181 @@
+package pkcs11prf_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/pqabelian/abelian-aip11-go/pkcs11prf"
+	"github.com/stretchr/testify/assert"
+)
+
+// The integration tests run against a SoftHSM2 token, initialized with e.g.
+//
+//	softhsm2-util --init-token --free --label aip11 --so-pin 0000 --pin 1234
+//
+// and configured by AIP11_PKCS11_MODULE, AIP11_PKCS11_TOKEN (default aip11)
+// and AIP11_PKCS11_PIN (default 1234). They are skipped if no module is found.
+// TestInTokenPRF runs only against a token with a KMAC mechanism, given by
+// AIP11_PKCS11_KMAC_MECHANISM.
+
+var softHSMModules = []string{
+	"/usr/lib/softhsm/libsofthsm2.so",
+	"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+	"/usr/local/lib/softhsm/libsofthsm2.so",
+	"/opt/homebrew/lib/softhsm/libsofthsm2.so",
+}
+
+func softHSMConfig(t *testing.T) pkcs11prf.Config {
+	config := pkcs11prf.Config{
+		Module:     os.Getenv("AIP11_PKCS11_MODULE"),
+		TokenLabel: getenv("AIP11_PKCS11_TOKEN", "aip11"),
+		PIN:        getenv("AIP11_PKCS11_PIN", "1234"),
+	}
+	for _, module := range softHSMModules {
+		if config.Module != "" {
+			break
+		}
+		if _, err := os.Stat(module); err == nil {
+			config.Module = module
+		}
+	}
+	if config.Module == "" {
+		t.Skip("no PKCS#11 module found, set AIP11_PKCS11_MODULE to run the SoftHSM2 tests")
+	}
+	return config
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func TestOpenRejects(t *testing.T) {
+	_, err := pkcs11prf.Open(pkcs11prf.Config{Module: "/nonexistent/libpkcs11.so"})
+	assert.ErrorIs(t, err, pkcs11prf.ErrModuleLoad, "Missing module should be rejected")
+}
+
+func TestSoftHSMWithoutKMAC(t *testing.T) {
+	config := softHSMConfig(t)
+	config.KMACMechanism = 0x80000000 | 0x4b4d4143 // a vendor mechanism SoftHSM2 lacks
+	_, err := pkcs11prf.Open(config)
+	assert.ErrorIs(t, err, pkcs11prf.ErrKMACUnsupported, "Token without KMAC should be rejected without fallback")
+
+	config.TokenLabel = "no-such-token"
+	config.AllowSoftwareFallback = true
+	_, err = pkcs11prf.Open(config)
+	assert.ErrorIs(t, err, pkcs11prf.ErrTokenNotFound, "Unknown token should be rejected")
+}
+
+func TestSoftHSMDerivation(t *testing.T) {
+	config := softHSMConfig(t)
+	config.AllowSoftwareFallback = true
+	backend, err := pkcs11prf.Open(config)
+	if !assert.NoError(t, err, "Backend should be opened correctly") {
+		return
+	}
+	defer backend.Close()
+	assert.False(t, backend.InToken(), "SoftHSM2 should use the software fallback")
+
+	deriver := aip11.NewDeriver(backend)
+	for i := 0; i < 3; i++ {
+		t.Run(fmt.Sprintf("seed %d", i), func(t *testing.T) {
+			entropySeed, err := aip11.SampleEntropySeed()
+			assert.NoError(t, err, "Entropy seed should be sampled correctly")
+			label := fmt.Sprintf("aip11-test-%d", i)
+			imported, err := backend.ImportSeed(label, entropySeed)
+			assert.NoError(t, err, "Entropy seed should be imported correctly")
+			defer backend.DestroyKey(imported)
+
+			key, err := backend.FindSeed(label)
+			assert.NoError(t, err, "Entropy seed should be found")
+			assert.Equal(t, 32, key.Len(), "Entropy seed should have its length")
+
+			masterSeed, err := deriver.EntropySeedToMasterSeed(key, []byte{})
+			assert.NoError(t, err, "Master seed should be generated correctly")
+			rootSeeds, err := deriver.MasterSeedToAccountRootSeeds(masterSeed)
+			assert.NoError(t, err, "Root seeds should be generated correctly")
+			assert.Equal(t, 5, len(rootSeeds), "Root seeds should be generated correctly")
+			publicRandRootSeed, err := deriver.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+			assert.NoError(t, err, "Public rand root seed should be generated correctly")
+
+			expectedMasterSeed, err := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+			assert.NoError(t, err, "Master seed should be generated correctly")
+			expectedRoot, err := aip11.MasterSeedToAccountPublicRandRootSeed(expectedMasterSeed)
+			assert.NoError(t, err, "Public rand root seed should be generated correctly")
+			for seqNo := uint32(0); seqNo < 4; seqNo++ {
+				publicRand, err := deriver.DerivePublicRand(publicRandRootSeed, seqNo)
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				expected, err := aip11.DerivePublicRand(expectedRoot, seqNo)
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				assert.Equal(t, expected, publicRand, "Public rand should match the software derivation")
+			}
+		})
+	}
+
+	_, err = backend.FindSeed("aip11-no-such-seed")
+	assert.ErrorIs(t, err, pkcs11prf.ErrKeyNotFound, "Unknown seed should not be found")
+	_, err = deriver.EntropySeedToMasterSeed(aip11.SoftwareKey(make([]byte, 32)), []byte{})
+	assert.ErrorIs(t, err, aip11.ErrPRFKeyUnsupported, "Software key should be rejected")
+}
+
+func TestSoftHSMPRF(t *testing.T) {
+	config := softHSMConfig(t)
+	config.AllowSoftwareFallback = true
+	backend, err := pkcs11prf.Open(config)
+	if !assert.NoError(t, err, "Backend should be opened correctly") {
+		return
+	}
+	defer backend.Close()
+	assertPRFMatchesSoftware(t, backend)
+}
+
+func TestInTokenPRF(t *testing.T) {
+	config := softHSMConfig(t)
+	mechanism := os.Getenv("AIP11_PKCS11_KMAC_MECHANISM")
+	if mechanism == "" {
+		t.Skip("no KMAC mechanism given, set AIP11_PKCS11_KMAC_MECHANISM to run the in-token tests")
+	}
+	m, err := strconv.ParseUint(mechanism, 0, 64)
+	if !assert.NoError(t, err, "KMAC mechanism should be parsed correctly") {
+		return
+	}
+	config.KMACMechanism = uint(m)
+	backend, err := pkcs11prf.Open(config)
+	if !assert.NoError(t, err, "Backend should be opened correctly") {
+		return
+	}
+	defer backend.Close()
+	assert.True(t, backend.InToken(), "Token with KMAC should evaluate PRF in the token")
+	assertPRFMatchesSoftware(t, backend)
+}
+
+// assertPRFMatchesSoftware checks the PRF and DeriveKey of the backend against
+// aip11.PRF.
+func assertPRFMatchesSoftware(t *testing.T, backend *pkcs11prf.Backend) {
+	seed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Seed should be sampled correctly")
+	key, err := backend.ImportSeed("aip11-test-prf", seed)
+	if !assert.NoError(t, err, "Seed should be imported correctly") {
+		return
+	}
+	defer backend.DestroyKey(key)
+
+	for _, input := range []string{"", "AccountMasterSeed", "PublicRandRootSeed", "00000000"} {
+		output, err := backend.PRF(key, []byte(input))
+		assert.NoError(t, err, "PRF should be evaluated correctly")
+		assert.Equal(t, aip11.PRF(seed, []byte(input)), output, "PRF of %q should match the software PRF", input)
+	}
+
+	derived, err := backend.DeriveKey(key, []byte("AccountMasterSeed"))
+	assert.NoError(t, err, "Key should be derived correctly")
+	assert.Equal(t, 64, derived.Len(), "Derived key should have its length")
+	output, err := backend.PRF(derived, []byte("PublicRandRootSeed"))
+	assert.NoError(t, err, "PRF should be evaluated correctly")
+	expected := aip11.PRF(aip11.PRF(seed, []byte("AccountMasterSeed")), []byte("PublicRandRootSeed"))
+	assert.Equal(t, expected, output, "PRF of a derived key should match the software PRF")
+}
@@ -0,0 +1,175 @@
+package aip11
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// This file provides the registry of root seed kinds.
+//
+// Every root seed is PRF(masterSeed, label) for the label of its kind. The
+// built-in kinds are those of AIP11 and AIP15, in the order returned by
+// MasterSeedToAccountRootSeeds. Further kinds, e.g. for address types under
+// development, can be registered at runtime. Registered kinds are
+// experimental: they are derived on testnet and regtest only, never on
+// mainnet, so a mainnet wallet never depends on them.
+//
+// A registered label must not start with any label the derivation uses, so
+// its PRF input differs from every other PRF input under the master seed.
+
+// Errors
+
+var (
+	ErrRootSeedKindInvalid      = errors.New("root seed kind is invalid")
+	ErrRootSeedKindRegistered   = errors.New("root seed kind is already registered")
+	ErrRootSeedKindUnknown      = errors.New("unknown root seed kind")
+	ErrRootSeedKindExperimental = errors.New("experimental root seed kind is not available on mainnet")
+)
+
+// RootSeedKind describes a kind of account root seed.
+type RootSeedKind struct {
+	// Name identifies the kind, e.g. RootSeedSpend.
+	Name string
+	// Label is the PRF input deriving the root seed from the master seed.
+	Label string
+	// Required reports whether every account derives the root seed. Optional
+	// root seeds are only used by the address types that need them.
+	Required bool
+	// AddressTypes are the address types that use the root seed.
+	AddressTypes []AddressType
+	// Experimental kinds are never derived on mainnet.
+	Experimental bool
+}
+
+// builtinRootSeedKinds are the root seed kinds of the specification, in the
+// order of MasterSeedToAccountRootSeeds.
+var builtinRootSeedKinds = []RootSeedKind{
+	{
+		Name:         RootSeedSpend,
+		Label:        "CoinSpendKeyRootSeed",
+		Required:     true,
+		AddressTypes: []AddressType{AddressTypeFullPrivacy, AddressTypePseudonym, AddressTypePseudonymCT},
+	},
+	{
+		Name:         RootSeedSerialNumber,
+		Label:        "CoinSerialNumberKeyRootSeed",
+		Required:     true,
+		AddressTypes: []AddressType{AddressTypeFullPrivacy},
+	},
+	{
+		Name:         RootSeedDetector,
+		Label:        "CoinDetectorRootKey",
+		Required:     true,
+		AddressTypes: []AddressType{AddressTypeFullPrivacy, AddressTypePseudonym, AddressTypePseudonymCT},
+	},
+	{
+		Name:         RootSeedVK,
+		Label:        "CoinValueKeyRootSeed",
+		Required:     true,
+		AddressTypes: []AddressType{AddressTypeFullPrivacy},
+	},
+	{
+		Name:         RootSeedVKAut,
+		Label:        "CoinValueKeyRootSeedAut",
+		AddressTypes: []AddressType{AddressTypePseudonymCT},
+	},
+}
+
+var rootSeedRegistry = struct {
+	sync.RWMutex
+	kinds []RootSeedKind
+}{}
+
+// RegisterRootSeedKind registers an experimental root seed kind. The name
+// follows the rules of sub-master path components and must not be
+// RootSeedPublicRand, which paths resolve to the public rand root seed.
+func RegisterRootSeedKind(kind RootSeedKind) error {
+	if !kind.Experimental || !validSubMasterPathComponent(kind.Name) || kind.Name == RootSeedPublicRand || kind.Label == "" {
+		return ErrRootSeedKindInvalid
+	}
+	for _, reserved := range derivationLabels {
+		if strings.HasPrefix(kind.Label, reserved) {
+			return ErrRootSeedKindInvalid
+		}
+	}
+
+	rootSeedRegistry.Lock()
+	defer rootSeedRegistry.Unlock()
+	for _, k := range allRootSeedKinds() {
+		if k.Name == kind.Name || k.Label == kind.Label {
+			return ErrRootSeedKindRegistered
+		}
+	}
+	kind.AddressTypes = slices.Clone(kind.AddressTypes)
+	rootSeedRegistry.kinds = append(rootSeedRegistry.kinds, kind)
+	return nil
+}
+
+// UnregisterRootSeedKind removes a registered kind. Built-in kinds cannot be
+// removed.
+func UnregisterRootSeedKind(name string) error {
+	rootSeedRegistry.Lock()
+	defer rootSeedRegistry.Unlock()
+	i := slices.IndexFunc(rootSeedRegistry.kinds, func(k RootSeedKind) bool { return k.Name == name })
+	if i < 0 {
+		return ErrRootSeedKindUnknown
+	}
+	rootSeedRegistry.kinds = slices.Delete(rootSeedRegistry.kinds, i, i+1)
+	return nil
+}
+
+// RootSeedKinds returns the built-in kinds followed by the registered kinds,
+// in registration order.
+func RootSeedKinds() []RootSeedKind {
+	rootSeedRegistry.RLock()
+	defer rootSeedRegistry.RUnlock()
+	kinds := allRootSeedKinds()
+	for i := range kinds {
+		kinds[i].AddressTypes = slices.Clone(kinds[i].AddressTypes)
+	}
+	return kinds
+}
+
+// allRootSeedKinds must be called with the registry locked.
+func allRootSeedKinds() []RootSeedKind {
+	return append(slices.Clone(builtinRootSeedKinds), rootSeedRegistry.kinds...)
+}
+
+// LookupRootSeedKind returns the kind with the given name.
+func LookupRootSeedKind(name string) (RootSeedKind, bool) {
+	for _, k := range RootSeedKinds() {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return RootSeedKind{}, false
+}
+
+// DerivedRootSeed is a root seed together with its kind.
+type DerivedRootSeed struct {
+	Kind RootSeedKind
+	Seed []byte
+}
+
+// MasterSeedToRootSeeds derives the root seeds of all kinds available on the
+// network from the master seed: the built-in kinds on mainnet, and the
+// registered kinds in addition on other networks.
+func MasterSeedToRootSeeds(masterSeed []byte, network Network) ([]DerivedRootSeed, error) {
+	keys, err := defaultDeriver.MasterSeedToRootSeeds(SoftwareKey(masterSeed), network)
+	if err != nil {
+		return nil, err
+	}
+	seeds := make([]DerivedRootSeed, len(keys))
+	for i, key := range keys {
+		seeds[i] = DerivedRootSeed{Kind: key.Kind, Seed: key.Seed.(SoftwareKey)}
+	}
+	return seeds, nil
+}
+
+// MasterSeedToRootSeed derives the root seed of the named kind from the
+// master seed for the network.
+func MasterSeedToRootSeed(masterSeed []byte, name string, network Network) ([]byte, error) {
+	return softwareKeyBytes(defaultDeriver.MasterSeedToRootSeed(SoftwareKey(masterSeed), name, network))
+}
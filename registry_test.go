@@ -0,0 +1,141 @@
+package aip11_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleRegisterRootSeedKind() {
+	_ = aip11.RegisterRootSeedKind(aip11.RootSeedKind{
+		Name:         "example-kind",
+		Label:        "ExampleKindRootSeed",
+		Experimental: true,
+	})
+	defer aip11.UnregisterRootSeedKind("example-kind")
+
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Testnet)
+	rootSeeds, _ := aip11.MasterSeedToRootSeeds(masterSeed, aip11.Testnet)
+	mainnetRootSeeds, _ := aip11.MasterSeedToRootSeeds(masterSeed, aip11.Mainnet)
+	fmt.Println(len(rootSeeds), len(mainnetRootSeeds))
+	// Output: 6 5
+}
+
+func TestMasterSeedToRootSeeds(t *testing.T) {
+	for i, v := range getAIP11Vector() {
+		t.Run(fmt.Sprintf("vector %d", i), func(t *testing.T) {
+			masterSeed, err := hex.DecodeString(v.masterSeed)
+			assert.NoError(t, err, "Master seed should be decoded correctly")
+
+			rootSeeds, err := aip11.MasterSeedToRootSeeds(masterSeed, aip11.Mainnet)
+			assert.NoError(t, err, "Root seeds should be generated correctly")
+			expected := []struct {
+				name string
+				seed string
+			}{
+				{name: aip11.RootSeedSpend, seed: v.rootSeeds.coinSpKeyRootSeed},
+				{name: aip11.RootSeedSerialNumber, seed: v.rootSeeds.coinSnKeyRootSeed},
+				{name: aip11.RootSeedDetector, seed: v.rootSeeds.coinDetectorRootKey},
+				{name: aip11.RootSeedVK, seed: v.rootSeeds.coinVKRootSeed},
+				{name: aip11.RootSeedVKAut, seed: v.rootSeeds.coinVKeyRootSeedAut},
+			}
+			assert.Equal(t, len(expected), len(rootSeeds), "Built-in root seeds should be derived")
+			for j, e := range expected {
+				assert.Equal(t, e.name, rootSeeds[j].Kind.Name, "Root seed kinds should be in order")
+				assert.Equal(t, e.seed, hex.EncodeToString(rootSeeds[j].Seed), "Root seed should be generated correctly")
+
+				seed, err := aip11.MasterSeedToRootSeed(masterSeed, e.name, aip11.Mainnet)
+				assert.NoError(t, err, "Root seed should be generated correctly")
+				assert.Equal(t, e.seed, hex.EncodeToString(seed), "Root seed should be generated correctly")
+			}
+		})
+	}
+}
+
+func TestRootSeedKinds(t *testing.T) {
+	kinds := aip11.RootSeedKinds()
+	assert.GreaterOrEqual(t, len(kinds), 5, "Built-in kinds should be registered")
+	for _, kind := range kinds[:4] {
+		assert.True(t, kind.Required, fmt.Sprintf("Kind %s should be required", kind.Name))
+	}
+	vkAut, ok := aip11.LookupRootSeedKind(aip11.RootSeedVKAut)
+	assert.True(t, ok, "Kind should be found")
+	assert.False(t, vkAut.Required, "CoinVKeyRootSeedAut should be optional")
+	assert.Equal(t, []aip11.AddressType{aip11.AddressTypePseudonymCT}, vkAut.AddressTypes, "CoinVKeyRootSeedAut should be used by type 3")
+
+	// The returned kinds are copies.
+	kinds[0].AddressTypes[0] = 0
+	kinds[0].Label = "changed"
+	spend, _ := aip11.LookupRootSeedKind(aip11.RootSeedSpend)
+	assert.Equal(t, "CoinSpendKeyRootSeed", spend.Label, "Built-in kinds should not be modifiable")
+	assert.Equal(t, aip11.AddressTypeFullPrivacy, spend.AddressTypes[0], "Built-in kinds should not be modifiable")
+}
+
+func TestRegisterRootSeedKind(t *testing.T) {
+	kind := aip11.RootSeedKind{
+		Name:         "test-kind",
+		Label:        "TestKindRootSeed",
+		AddressTypes: []aip11.AddressType{aip11.AddressType(4)},
+		Experimental: true,
+	}
+	assert.NoError(t, aip11.RegisterRootSeedKind(kind), "Kind should be registered correctly")
+	defer aip11.UnregisterRootSeedKind(kind.Name)
+	assert.ErrorIs(t, aip11.RegisterRootSeedKind(kind), aip11.ErrRootSeedKindRegistered, "Kind should be registered once")
+
+	masterSeed := make([]byte, 64)
+	seed, err := aip11.MasterSeedToRootSeed(masterSeed, kind.Name, aip11.Testnet)
+	assert.NoError(t, err, "Experimental root seed should be generated on testnet")
+	assert.Equal(t, aip11.PRF(masterSeed, []byte(kind.Label)), seed, "Root seed should be PRF of its label")
+	_, err = aip11.MasterSeedToRootSeed(masterSeed, kind.Name, aip11.Mainnet)
+	assert.ErrorIs(t, err, aip11.ErrRootSeedKindExperimental, "Experimental root seed should not be generated on mainnet")
+
+	rootSeeds, err := aip11.MasterSeedToRootSeeds(masterSeed, aip11.Regtest)
+	assert.NoError(t, err, "Root seeds should be generated correctly")
+	assert.Equal(t, kind.Name, rootSeeds[len(rootSeeds)-1].Kind.Name, "Registered kinds should follow the built-in kinds")
+	accountRootSeeds, err := aip11.MasterSeedToAccountRootSeeds(masterSeed)
+	assert.NoError(t, err, "Root seeds should be generated correctly")
+	assert.Equal(t, 5, len(accountRootSeeds), "Account root seeds should not include registered kinds")
+
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	testnetMasterSeed, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, aip11.Testnet)
+	assert.NoError(t, err, "Master seed should be generated correctly")
+	expected, err := aip11.MasterSeedToRootSeed(testnetMasterSeed, kind.Name, aip11.Testnet)
+	assert.NoError(t, err, "Experimental root seed should be generated on testnet")
+	result, err := aip11.ResolveDerivationPath("aip11:net=testnet/rootseeds/test-kind", entropySeed)
+	if assert.NoError(t, err, "Derivation path should select registered kinds") {
+		assert.Equal(t, expected, result.Value, "Derivation path should resolve the registered kind")
+	}
+	_, err = aip11.ResolveDerivationPath("aip11:rootseeds/test-kind", entropySeed)
+	assert.ErrorIs(t, err, aip11.ErrRootSeedKindExperimental, "Derivation path should not resolve experimental kinds on mainnet")
+
+	assert.NoError(t, aip11.UnregisterRootSeedKind(kind.Name), "Kind should be unregistered correctly")
+	_, err = aip11.MasterSeedToRootSeed(masterSeed, kind.Name, aip11.Testnet)
+	assert.ErrorIs(t, err, aip11.ErrRootSeedKindUnknown, "Unregistered kind should be unknown")
+	assert.ErrorIs(t, aip11.UnregisterRootSeedKind(aip11.RootSeedSpend), aip11.ErrRootSeedKindUnknown, "Built-in kinds should not be unregistered")
+}
+
+func TestRegisterRootSeedKindRejects(t *testing.T) {
+	testCases := []struct {
+		name string
+		kind aip11.RootSeedKind
+	}{
+		{name: "not experimental", kind: aip11.RootSeedKind{Name: "kind", Label: "KindRootSeed"}},
+		{name: "invalid name", kind: aip11.RootSeedKind{Name: "a/b", Label: "KindRootSeed", Experimental: true}},
+		{name: "public rand name", kind: aip11.RootSeedKind{Name: aip11.RootSeedPublicRand, Label: "KindRootSeed", Experimental: true}},
+		{name: "empty label", kind: aip11.RootSeedKind{Name: "kind", Experimental: true}},
+		{name: "built-in label", kind: aip11.RootSeedKind{Name: "kind", Label: "CoinSpendKeyRootSeed", Experimental: true}},
+		{name: "label extending a derivation label", kind: aip11.RootSeedKind{Name: "kind", Label: "SubMasterSeedX", Experimental: true}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, aip11.RegisterRootSeedKind(tc.kind), aip11.ErrRootSeedKindInvalid, "Kind should be rejected")
+		})
+	}
+	assert.ErrorIs(t, aip11.RegisterRootSeedKind(aip11.RootSeedKind{Name: aip11.RootSeedSpend, Label: "OtherRootSeed", Experimental: true}),
+		aip11.ErrRootSeedKindRegistered, "Built-in name should be rejected")
+}
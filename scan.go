@@ -0,0 +1,118 @@
+package aip11
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// This file provides gap-limit scanning over deterministic public rands, used
+// to find how far a restored wallet got.
+
+// Errors
+
+var (
+	ErrGapLimitInvalid = errors.New("gap limit must be positive")
+)
+
+// DefaultGapLimit is the number of consecutive unused sequence numbers after
+// which a scan stops by default.
+const DefaultGapLimit = 20
+
+// UsageOracle reports whether a public rand has been used, e.g. by querying
+// the chain.
+type UsageOracle interface {
+	IsUsed(ctx context.Context, seqNo uint32, publicRand []byte) (bool, error)
+}
+
+// ScanResult is the outcome of ScanPublicRands.
+type ScanResult struct {
+	// Found reports whether any used sequence number was found.
+	Found bool
+	// HighestUsed is the highest used sequence number, valid if Found.
+	HighestUsed uint32
+	// Scanned is the number of sequence numbers queried.
+	Scanned uint64
+	// Start is the sequence number the scan started at.
+	Start uint32
+}
+
+// NextUnused returns the first sequence number after the highest used one, or
+// the start of the scan if none was used, so that a resumed scan never moves
+// back to sequence numbers before its start.
+func (r ScanResult) NextUnused() uint64 {
+	if !r.Found {
+		return uint64(r.Start)
+	}
+	return uint64(r.HighestUsed) + 1
+}
+
+// ScanPublicRands derives public rands in increasing sequence number order
+// from 0 and queries the oracle for each, until gapLimit consecutive sequence
+// numbers are unused or the sequence numbers run out after MaxSeqNo.
+func ScanPublicRands(ctx context.Context, publicRandRootSeed []byte, oracle UsageOracle, gapLimit uint32) (ScanResult, error) {
+	return ScanPublicRandsFrom(ctx, publicRandRootSeed, 0, oracle, gapLimit)
+}
+
+// ScanPublicRandsFrom is ScanPublicRands starting at sequence number start,
+// e.g. to resume a scan at the NextUnused of an earlier one.
+func ScanPublicRandsFrom(ctx context.Context, publicRandRootSeed []byte, start uint32, oracle UsageOracle, gapLimit uint32) (ScanResult, error) {
+	d, err := newPublicRandDeriver(publicRandRootSeed)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	if gapLimit == 0 {
+		return ScanResult{}, ErrGapLimitInvalid
+	}
+
+	result := ScanResult{Start: start}
+	gap := uint32(0)
+	for seqNo, publicRand := range d.through(start, MaxSeqNo) {
+		if err := ctx.Err(); err != nil {
+			return ScanResult{}, err
+		}
+		used, err := oracle.IsUsed(ctx, seqNo, publicRand)
+		if err != nil {
+			return ScanResult{}, err
+		}
+		result.Scanned++
+		if used {
+			result.Found = true
+			result.HighestUsed = seqNo
+			gap = 0
+			continue
+		}
+		gap++
+		if gap == gapLimit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// MemoryUsageOracle is an in-memory UsageOracle, e.g. for tests. It is safe
+// for concurrent use.
+type MemoryUsageOracle struct {
+	mu   sync.RWMutex
+	used map[string]struct{}
+}
+
+// NewMemoryUsageOracle creates a MemoryUsageOracle in which no public rand is used.
+func NewMemoryUsageOracle() *MemoryUsageOracle {
+	return &MemoryUsageOracle{used: map[string]struct{}{}}
+}
+
+// MarkUsed marks the public rand as used.
+func (o *MemoryUsageOracle) MarkUsed(publicRand []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.used[string(publicRand)] = struct{}{}
+}
+
+// IsUsed reports whether the public rand has been marked as used.
+func (o *MemoryUsageOracle) IsUsed(ctx context.Context, seqNo uint32, publicRand []byte) (bool, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	_, ok := o.used[string(publicRand)]
+	return ok, nil
+}
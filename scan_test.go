@@ -0,0 +1,136 @@
+package aip11_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleScanPublicRands() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	publicRandRootSeed, _ := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+
+	oracle := aip11.NewMemoryUsageOracle()
+	for _, seqNo := range []uint32{0, 1, 5} {
+		publicRand, _ := aip11.DerivePublicRand(publicRandRootSeed, seqNo)
+		oracle.MarkUsed(publicRand)
+	}
+
+	result, _ := aip11.ScanPublicRands(context.Background(), publicRandRootSeed, oracle, aip11.DefaultGapLimit)
+	fmt.Println(result.HighestUsed, result.NextUnused())
+	// Output: 5 6
+}
+
+func TestScanPublicRands(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+
+	testCases := []struct {
+		name     string
+		used     []uint32
+		gapLimit uint32
+		expected aip11.ScanResult
+	}{
+		{name: "Unused wallet", used: nil, gapLimit: 5, expected: aip11.ScanResult{Scanned: 5}},
+		{name: "Contiguous use", used: []uint32{0, 1, 2}, gapLimit: 5, expected: aip11.ScanResult{Found: true, HighestUsed: 2, Scanned: 8}},
+		{name: "Gap within limit", used: []uint32{0, 4}, gapLimit: 4, expected: aip11.ScanResult{Found: true, HighestUsed: 4, Scanned: 9}},
+		{name: "Gap at limit", used: []uint32{0, 5}, gapLimit: 4, expected: aip11.ScanResult{Found: true, HighestUsed: 0, Scanned: 5}},
+		{name: "Gap limit 1", used: []uint32{0, 1, 3}, gapLimit: 1, expected: aip11.ScanResult{Found: true, HighestUsed: 1, Scanned: 3}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oracle := aip11.NewMemoryUsageOracle()
+			for _, seqNo := range tc.used {
+				publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, seqNo)
+				assert.NoError(t, err, "Public rand should be generated correctly")
+				oracle.MarkUsed(publicRand)
+			}
+			result, err := aip11.ScanPublicRands(context.Background(), publicRandRootSeed, oracle, tc.gapLimit)
+			assert.NoError(t, err, "Scan should succeed")
+			assert.Equal(t, tc.expected, result, "Scan result should be the same")
+		})
+	}
+}
+
+type allUsedOracle struct{}
+
+func (allUsedOracle) IsUsed(ctx context.Context, seqNo uint32, publicRand []byte) (bool, error) {
+	return true, nil
+}
+
+func TestScanPublicRandsFrom(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+
+	oracle := aip11.NewMemoryUsageOracle()
+	for _, seqNo := range []uint32{0, 1, 10} {
+		publicRand, err := aip11.DerivePublicRand(publicRandRootSeed, seqNo)
+		assert.NoError(t, err, "Public rand should be generated correctly")
+		oracle.MarkUsed(publicRand)
+	}
+	result, err := aip11.ScanPublicRandsFrom(context.Background(), publicRandRootSeed, 8, oracle, 5)
+	assert.NoError(t, err, "Scan should succeed")
+	assert.Equal(t, aip11.ScanResult{Found: true, HighestUsed: 10, Scanned: 8, Start: 8}, result, "Resumed scan result should be the same")
+
+	result, err = aip11.ScanPublicRandsFrom(context.Background(), publicRandRootSeed, aip11.MaxSeqNo-2, allUsedOracle{}, 5)
+	assert.NoError(t, err, "Scan should succeed")
+	assert.Equal(t, aip11.ScanResult{Found: true, HighestUsed: aip11.MaxSeqNo, Scanned: 3, Start: aip11.MaxSeqNo - 2}, result, "Scan should include MaxSeqNo")
+
+	result, err = aip11.ScanPublicRandsFrom(context.Background(), publicRandRootSeed, aip11.MaxSeqNo, oracle, 5)
+	assert.NoError(t, err, "Scan should succeed")
+	assert.Equal(t, aip11.ScanResult{Scanned: 1, Start: aip11.MaxSeqNo}, result, "Scan should stop after MaxSeqNo")
+	assert.Equal(t, uint64(aip11.MaxSeqNo), result.NextUnused(), "Scan without use should resume at its start")
+
+	result, err = aip11.ScanPublicRandsFrom(context.Background(), publicRandRootSeed, 11, oracle, 5)
+	assert.NoError(t, err, "Scan should succeed")
+	assert.Equal(t, aip11.ScanResult{Scanned: 5, Start: 11}, result, "Resumed scan without use should be the same")
+	assert.Equal(t, uint64(11), result.NextUnused(), "Scan without use should resume at its start")
+}
+
+type failingUsageOracle struct {
+	failAt uint32
+	err    error
+}
+
+func (o failingUsageOracle) IsUsed(ctx context.Context, seqNo uint32, publicRand []byte) (bool, error) {
+	if seqNo == o.failAt {
+		return false, o.err
+	}
+	return true, nil
+}
+
+func TestScanPublicRandsErrors(t *testing.T) {
+	publicRandRootSeed := make([]byte, 64)
+	oracle := aip11.NewMemoryUsageOracle()
+
+	_, err := aip11.ScanPublicRands(context.Background(), publicRandRootSeed[:32], oracle, 5)
+	assert.ErrorIs(t, err, aip11.ErrPublicRandRootSeedInvalid, "Invalid public rand root seed should be rejected")
+	_, err = aip11.ScanPublicRands(context.Background(), publicRandRootSeed, oracle, 0)
+	assert.ErrorIs(t, err, aip11.ErrGapLimitInvalid, "Zero gap limit should be rejected")
+
+	errOracle := errors.New("node unavailable")
+	_, err = aip11.ScanPublicRands(context.Background(), publicRandRootSeed, failingUsageOracle{failAt: 3, err: errOracle}, 5)
+	assert.ErrorIs(t, err, errOracle, "Oracle error should be returned")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = aip11.ScanPublicRands(ctx, publicRandRootSeed, oracle, 5)
+	assert.ErrorIs(t, err, context.Canceled, "Cancellation should be reported")
+}
+
+func TestMemoryUsageOracle(t *testing.T) {
+	oracle := aip11.NewMemoryUsageOracle()
+	for i := 0; i < 3; i++ {
+		publicRand := []byte(fmt.Sprintf("public rand %d", i))
+		used, err := oracle.IsUsed(context.Background(), uint32(i), publicRand)
+		assert.NoError(t, err, "Oracle should not fail")
+		assert.False(t, used, "Public rand should not be used")
+		oracle.MarkUsed(publicRand)
+		used, err = oracle.IsUsed(context.Background(), uint32(i), publicRand)
+		assert.NoError(t, err, "Oracle should not fail")
+		assert.True(t, used, "Public rand should be used")
+	}
+}
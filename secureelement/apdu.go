@@ -0,0 +1,169 @@
+// Package secureelement provides an emulated secure element that holds an
+// aip11 entropy seed and exposes derivation commands over an APDU-style
+// protocol, together with the host-side client.
+//
+// A command is encoded as
+//
+//	CLA (1 byte) || INS (1 byte) || P1 (1 byte) || P2 (1 byte) || uint16_be(len(data)) || data
+//
+// and a response as data || SW1 || SW2. CLA is always ClassAIP11. The
+// commands are
+//
+//	INS  name                 data                                     response
+//	0x10 GENERATE             network || PIN                           -
+//	0x12 RESTORE              network || entropySeed (32) || PIN       -
+//	0x20 VERIFY PIN           PIN                                      -
+//	0x24 CHANGE PIN           len(old) || old PIN || new PIN           -
+//	0x30 GET FINGERPRINT      -                                        fingerprint (8)
+//	0x32 DERIVE PUBLIC RANDS  uint32_be(start) || uint16_be(count)     count public rands
+//	0x34 EXPORT WATCH-ONLY    -                                        watch-only account bundle
+//	0x40 GET STATUS           -                                        initialized || network || remaining PIN attempts
+//
+// GENERATE and RESTORE are accepted only by an empty element; the other
+// commands except GET STATUS fail with SWConditionsNotSatisfied on an empty
+// element, and the derivation commands require a verified PIN. A command that
+// fails inside the element, e.g. for lack of randomness, fails with
+// SWInternalError. After MaxPINAttempts consecutive wrong PINs the element
+// erases its seed.
+package secureelement
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Errors
+
+var (
+	ErrAPDUMalformed      = errors.New("APDU is malformed")
+	ErrNotInitialized     = errors.New("secure element holds no seed")
+	ErrAlreadyInitialized = errors.New("secure element already holds a seed")
+	ErrPINRequired        = errors.New("PIN verification required")
+	ErrPINIncorrect       = errors.New("PIN is incorrect")
+	ErrPINBlocked         = errors.New("PIN is blocked and the seed was erased")
+	ErrPINInvalid         = errors.New("PIN must be 4 to 32 bytes")
+	ErrWrongData          = errors.New("secure element rejected the command data")
+	ErrWrongLength        = errors.New("secure element rejected the command length")
+	ErrNotSupported       = errors.New("command is not supported")
+	ErrInternal           = errors.New("secure element failed internally")
+)
+
+// ClassAIP11 is the class byte of all commands.
+const ClassAIP11 = 0xb0
+
+// Instructions.
+const (
+	InsGenerate          = 0x10
+	InsRestore           = 0x12
+	InsVerifyPIN         = 0x20
+	InsChangePIN         = 0x24
+	InsGetFingerprint    = 0x30
+	InsDerivePublicRands = 0x32
+	InsExportWatchOnly   = 0x34
+	InsGetStatus         = 0x40
+)
+
+// Status words.
+const (
+	SWSuccess                = 0x9000
+	SWWrongLength            = 0x6700
+	SWSecurityNotSatisfied   = 0x6982
+	SWPINBlocked             = 0x6983
+	SWConditionsNotSatisfied = 0x6985
+	SWCommandNotAllowed      = 0x6986
+	SWWrongData              = 0x6a80
+	SWInsNotSupported        = 0x6d00
+	SWClaNotSupported        = 0x6e00
+	SWInternalError          = 0x6f00
+	// SWPINIncorrect is combined with the remaining attempts as 0x63c0 | n.
+	SWPINIncorrect = 0x63c0
+)
+
+const (
+	// MaxPINAttempts is the number of consecutive wrong PINs after which the
+	// element erases its seed.
+	MaxPINAttempts = 3
+	// MaxPublicRandsPerCommand is the maximum count of DERIVE PUBLIC RANDS.
+	MaxPublicRandsPerCommand = 64
+
+	minPINLength = 4
+	maxPINLength = 32
+)
+
+// Command is an APDU command.
+type Command struct {
+	Ins  byte
+	P1   byte
+	P2   byte
+	Data []byte
+}
+
+// MarshalBinary encodes the command.
+func (c *Command) MarshalBinary() ([]byte, error) {
+	if len(c.Data) > 0xffff {
+		return nil, ErrAPDUMalformed
+	}
+	apdu := []byte{ClassAIP11, c.Ins, c.P1, c.P2}
+	apdu = binary.BigEndian.AppendUint16(apdu, uint16(len(c.Data)))
+	return append(apdu, c.Data...), nil
+}
+
+// parseCommand decodes a command, returning the status word to answer with on
+// failure.
+func parseCommand(apdu []byte) (*Command, uint16) {
+	if len(apdu) < 6 || int(binary.BigEndian.Uint16(apdu[4:])) != len(apdu)-6 {
+		return nil, SWWrongLength
+	}
+	if apdu[0] != ClassAIP11 {
+		return nil, SWClaNotSupported
+	}
+	return &Command{Ins: apdu[1], P1: apdu[2], P2: apdu[3], Data: apdu[6:]}, SWSuccess
+}
+
+// Response is an APDU response.
+type Response struct {
+	Data []byte
+	SW   uint16
+}
+
+func (r *Response) marshal() []byte {
+	return binary.BigEndian.AppendUint16(append([]byte{}, r.Data...), r.SW)
+}
+
+// ParseResponse decodes a response.
+func ParseResponse(apdu []byte) (*Response, error) {
+	if len(apdu) < 2 {
+		return nil, ErrAPDUMalformed
+	}
+	n := len(apdu) - 2
+	return &Response{Data: apdu[:n], SW: binary.BigEndian.Uint16(apdu[n:])}, nil
+}
+
+// Err returns the error the status word stands for, or nil on success.
+func (r *Response) Err() error {
+	switch {
+	case r.SW == SWSuccess:
+		return nil
+	case r.SW&0xfff0 == SWPINIncorrect:
+		return fmt.Errorf("%w: %d attempts remaining", ErrPINIncorrect, r.SW&0x000f)
+	case r.SW == SWSecurityNotSatisfied:
+		return ErrPINRequired
+	case r.SW == SWPINBlocked:
+		return ErrPINBlocked
+	case r.SW == SWConditionsNotSatisfied:
+		return ErrNotInitialized
+	case r.SW == SWCommandNotAllowed:
+		return ErrAlreadyInitialized
+	case r.SW == SWWrongLength:
+		return ErrWrongLength
+	case r.SW == SWWrongData:
+		return ErrWrongData
+	case r.SW == SWInsNotSupported, r.SW == SWClaNotSupported:
+		return ErrNotSupported
+	case r.SW == SWInternalError:
+		return ErrInternal
+	default:
+		return fmt.Errorf("secure element returned status %04x", r.SW)
+	}
+}
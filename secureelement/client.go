@@ -0,0 +1,162 @@
+package secureelement
+
+import (
+	"encoding/binary"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+)
+
+// Client is the host side of the protocol.
+type Client struct {
+	transport Transport
+}
+
+// NewClient creates a client talking to the secure element over the
+// transport, e.g. an Emulator.
+func NewClient(transport Transport) *Client {
+	return &Client{transport: transport}
+}
+
+// Status is the state reported by GET STATUS.
+type Status struct {
+	Initialized       bool
+	Network           aip11.Network
+	RemainingAttempts int
+}
+
+func (c *Client) transmit(command *Command) ([]byte, error) {
+	apdu, err := command.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	responseAPDU, err := c.transport.Transmit(apdu)
+	if err != nil {
+		return nil, err
+	}
+	response, err := ParseResponse(responseAPDU)
+	if err != nil {
+		return nil, err
+	}
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// Generate lets an empty secure element sample a new entropy seed for the
+// network, protected by the PIN. The seed is never revealed to the host.
+func (c *Client) Generate(network aip11.Network, pin string) error {
+	if !validPIN([]byte(pin)) {
+		return ErrPINInvalid
+	}
+	data := append([]byte{byte(network)}, pin...)
+	_, err := c.transmit(&Command{Ins: InsGenerate, Data: data})
+	return err
+}
+
+// Restore loads an existing entropy seed for the network into an empty secure
+// element, protected by the PIN.
+func (c *Client) Restore(network aip11.Network, entropySeed []byte, pin string) error {
+	if len(entropySeed) != 32 {
+		return aip11.ErrEntropySeedInvalid
+	}
+	if !validPIN([]byte(pin)) {
+		return ErrPINInvalid
+	}
+	data := append([]byte{byte(network)}, entropySeed...)
+	data = append(data, pin...)
+	_, err := c.transmit(&Command{Ins: InsRestore, Data: data})
+	clear(data)
+	return err
+}
+
+// VerifyPIN verifies the PIN, unlocking the derivation commands until the
+// secure element is reset. A wrong PIN fails with ErrPINIncorrect, and the
+// last allowed wrong PIN with ErrPINBlocked.
+func (c *Client) VerifyPIN(pin string) error {
+	_, err := c.transmit(&Command{Ins: InsVerifyPIN, Data: []byte(pin)})
+	return err
+}
+
+// ChangePIN replaces the PIN. A wrong old PIN counts as a failed attempt.
+func (c *Client) ChangePIN(oldPIN, newPIN string) error {
+	if len(oldPIN) > maxPINLength || !validPIN([]byte(newPIN)) {
+		return ErrPINInvalid
+	}
+	data := append([]byte{byte(len(oldPIN))}, oldPIN...)
+	data = append(data, newPIN...)
+	_, err := c.transmit(&Command{Ins: InsChangePIN, Data: data})
+	return err
+}
+
+// Status returns the state of the secure element.
+func (c *Client) Status() (Status, error) {
+	data, err := c.transmit(&Command{Ins: InsGetStatus})
+	if err != nil {
+		return Status{}, err
+	}
+	if len(data) != 3 {
+		return Status{}, ErrAPDUMalformed
+	}
+	return Status{
+		Initialized:       data[0] == 1,
+		Network:           aip11.Network(data[1]),
+		RemainingAttempts: int(data[2]),
+	}, nil
+}
+
+// Fingerprint returns the fingerprint of the master seed.
+func (c *Client) Fingerprint() (aip11.Fingerprint, error) {
+	data, err := c.transmit(&Command{Ins: InsGetFingerprint})
+	if err != nil {
+		return aip11.Fingerprint{}, err
+	}
+	if len(data) != aip11.FingerprintSize {
+		return aip11.Fingerprint{}, ErrAPDUMalformed
+	}
+	return aip11.Fingerprint(data), nil
+}
+
+// PublicRands returns the public rands with sequence numbers start to
+// start+count-1, in as many commands as needed.
+func (c *Client) PublicRands(start, count uint32) ([][]byte, error) {
+	if count == 0 || uint64(start)+uint64(count)-1 > uint64(aip11.MaxSeqNo) {
+		return nil, aip11.ErrSeqNoRangeInvalid
+	}
+
+	publicRands := make([][]byte, 0, count)
+	for done := uint32(0); done < count; {
+		n := min(count-done, MaxPublicRandsPerCommand)
+		data := binary.BigEndian.AppendUint32(nil, start+done)
+		data = binary.BigEndian.AppendUint16(data, uint16(n))
+		response, err := c.transmit(&Command{Ins: InsDerivePublicRands, Data: data})
+		if err != nil {
+			return nil, err
+		}
+		if len(response) != int(n)*aip11.PublicRandSize {
+			return nil, ErrAPDUMalformed
+		}
+		for i := 0; i < int(n); i++ {
+			publicRands = append(publicRands, response[i*aip11.PublicRandSize:(i+1)*aip11.PublicRandSize])
+		}
+		done += n
+	}
+	return publicRands, nil
+}
+
+// WatchOnlyBundle exports the watch-only account bundle, checking that it is
+// for the expected network.
+func (c *Client) WatchOnlyBundle(network aip11.Network) (*aip11.AccountBundle, error) {
+	data, err := c.transmit(&Command{Ins: InsExportWatchOnly})
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := aip11.UnmarshalAccountBundle(data, network)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Role != aip11.RoleWatchOnly {
+		return nil, aip11.ErrAccountRoleMismatch
+	}
+	return bundle, nil
+}
@@ -0,0 +1,139 @@
+package secureelement_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/pqabelian/abelian-aip11-go/secureelement"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleClient() {
+	client := secureelement.NewClient(secureelement.NewEmulator())
+	_ = client.Generate(aip11.Mainnet, "1234")
+	_ = client.VerifyPIN("1234")
+
+	publicRands, _ := client.PublicRands(0, 100)
+	bundle, _ := client.WatchOnlyBundle(aip11.Mainnet)
+	fmt.Println(len(publicRands), bundle.Role, bundle.CoinSpKeyRootSeed == nil)
+	// Output: 100 watch-only true
+}
+
+func TestClientMatchesSoftwareDerivation(t *testing.T) {
+	entropySeed := bytes.Repeat([]byte{0x5a}, 32)
+
+	testCases := []struct {
+		name    string
+		network aip11.Network
+	}{
+		{"mainnet", aip11.Mainnet},
+		{"testnet", aip11.Testnet},
+		{"regtest", aip11.Regtest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			masterSeed, err := aip11.EntropySeedToNetworkMasterSeed(entropySeed, []byte{}, tc.network)
+			assert.NoError(t, err, "Master seed should be derived correctly")
+
+			client := secureelement.NewClient(secureelement.NewEmulator())
+			assert.NoError(t, client.Restore(tc.network, entropySeed, "123456"), "Seed should be restored correctly")
+			assert.NoError(t, client.VerifyPIN("123456"), "PIN should be verified correctly")
+
+			fingerprint, err := client.Fingerprint()
+			assert.NoError(t, err, "Fingerprint should be returned correctly")
+			expectedFingerprint, err := aip11.MasterSeedToFingerprint(masterSeed)
+			assert.NoError(t, err, "Fingerprint should be computed correctly")
+			assert.Equal(t, expectedFingerprint, fingerprint, "Fingerprint should be computed correctly")
+
+			root, err := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+			assert.NoError(t, err, "Public rand root seed should be derived correctly")
+			start := uint32(1000)
+			publicRands, err := client.PublicRands(start, 2*secureelement.MaxPublicRandsPerCommand+3)
+			assert.NoError(t, err, "Public rands should be derived correctly")
+			assert.Len(t, publicRands, 2*secureelement.MaxPublicRandsPerCommand+3, "Public rands should be derived correctly")
+			for i, publicRand := range publicRands {
+				expected, err := aip11.DerivePublicRand(root, start+uint32(i))
+				assert.NoError(t, err, "Public rand should be derived correctly")
+				assert.Equal(t, expected, publicRand, "Public rand should be derived correctly")
+			}
+
+			bundle, err := client.WatchOnlyBundle(tc.network)
+			assert.NoError(t, err, "Watch-only bundle should be exported correctly")
+			expectedBundle, err := aip11.NewAccountBundle(masterSeed, aip11.RoleWatchOnly, tc.network)
+			assert.NoError(t, err, "Watch-only bundle should be derived correctly")
+			assert.Equal(t, expectedBundle, bundle, "Watch-only bundle should be exported correctly")
+		})
+	}
+}
+
+func TestClientPIN(t *testing.T) {
+	emulator := secureelement.NewEmulator()
+	client := secureelement.NewClient(emulator)
+
+	_, err := client.Fingerprint()
+	assert.ErrorIs(t, err, secureelement.ErrNotInitialized, "Empty element should be rejected correctly")
+	assert.ErrorIs(t, client.Generate(aip11.Mainnet, "123"), secureelement.ErrPINInvalid, "Short PIN should be rejected correctly")
+	assert.NoError(t, client.Generate(aip11.Mainnet, "1234"), "Seed should be generated correctly")
+	assert.ErrorIs(t, client.Generate(aip11.Mainnet, "1234"), secureelement.ErrAlreadyInitialized, "Second seed should be rejected correctly")
+
+	_, err = client.Fingerprint()
+	assert.ErrorIs(t, err, secureelement.ErrPINRequired, "Unverified PIN should be rejected correctly")
+	assert.NoError(t, client.VerifyPIN("1234"), "PIN should be verified correctly")
+	fingerprint, err := client.Fingerprint()
+	assert.NoError(t, err, "Fingerprint should be returned correctly")
+
+	emulator.Reset()
+	_, err = client.Fingerprint()
+	assert.ErrorIs(t, err, secureelement.ErrPINRequired, "Reset should clear the PIN verification correctly")
+
+	assert.ErrorIs(t, client.ChangePIN("0000", "5678"), secureelement.ErrPINIncorrect, "Wrong old PIN should be rejected correctly")
+	status, err := client.Status()
+	assert.NoError(t, err, "Status should be returned correctly")
+	assert.Equal(t, secureelement.MaxPINAttempts-1, status.RemainingAttempts, "Wrong old PIN should count as an attempt correctly")
+	assert.NoError(t, client.ChangePIN("1234", "5678"), "PIN should be changed correctly")
+	assert.ErrorIs(t, client.VerifyPIN("1234"), secureelement.ErrPINIncorrect, "Old PIN should be rejected correctly")
+	assert.NoError(t, client.VerifyPIN("5678"), "New PIN should be verified correctly")
+	status, err = client.Status()
+	assert.NoError(t, err, "Status should be returned correctly")
+	assert.Equal(t, secureelement.MaxPINAttempts, status.RemainingAttempts, "Correct PIN should reset the attempts correctly")
+
+	changed, err := client.Fingerprint()
+	assert.NoError(t, err, "Fingerprint should be returned correctly")
+	assert.Equal(t, fingerprint, changed, "PIN change should keep the seed correctly")
+}
+
+func TestClientPINBlocked(t *testing.T) {
+	client := secureelement.NewClient(secureelement.NewEmulator())
+	assert.NoError(t, client.Generate(aip11.Testnet, "1234"), "Seed should be generated correctly")
+
+	for i := 1; i < secureelement.MaxPINAttempts; i++ {
+		err := client.VerifyPIN("0000")
+		assert.ErrorIs(t, err, secureelement.ErrPINIncorrect, "Wrong PIN should be rejected correctly")
+		assert.ErrorContains(t, err, fmt.Sprintf("%d attempts remaining", secureelement.MaxPINAttempts-i), "Remaining attempts should be reported correctly")
+	}
+	assert.ErrorIs(t, client.VerifyPIN("0000"), secureelement.ErrPINBlocked, "Last wrong PIN should block correctly")
+	assert.ErrorIs(t, client.VerifyPIN("1234"), secureelement.ErrNotInitialized, "Blocked element should be erased correctly")
+
+	status, err := client.Status()
+	assert.NoError(t, err, "Status should be returned correctly")
+	assert.Equal(t, secureelement.Status{}, status, "Blocked element should be erased correctly")
+
+	assert.NoError(t, client.Generate(aip11.Testnet, "1234"), "Erased element should accept a new seed correctly")
+}
+
+func TestClientRejects(t *testing.T) {
+	client := secureelement.NewClient(secureelement.NewEmulator())
+	assert.ErrorIs(t, client.Restore(aip11.Mainnet, make([]byte, 31), "1234"), aip11.ErrEntropySeedInvalid, "Short entropy seed should be rejected correctly")
+	assert.ErrorIs(t, client.Restore(aip11.Network(9), make([]byte, 32), "1234"), secureelement.ErrWrongData, "Unknown network should be rejected correctly")
+	assert.NoError(t, client.Restore(aip11.Mainnet, make([]byte, 32), "1234"), "Seed should be restored correctly")
+	assert.NoError(t, client.VerifyPIN("1234"), "PIN should be verified correctly")
+
+	_, err := client.PublicRands(0, 0)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoRangeInvalid, "Empty range should be rejected correctly")
+	_, err = client.PublicRands(aip11.MaxSeqNo, 2)
+	assert.ErrorIs(t, err, aip11.ErrSeqNoRangeInvalid, "Overflowing range should be rejected correctly")
+	_, err = client.WatchOnlyBundle(aip11.Testnet)
+	assert.Error(t, err, "Bundle of another network should be rejected correctly")
+}
@@ -0,0 +1,220 @@
+package secureelement
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"sync"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"golang.org/x/crypto/sha3"
+)
+
+// Transport exchanges APDUs with a secure element.
+type Transport interface {
+	Transmit(command []byte) ([]byte, error)
+}
+
+// Emulator is an in-process secure element holding an entropy seed, from
+// which it derives the master seed of its network with an empty
+// customizationContext. Its seeds never leave it; only fingerprints, public
+// rands and watch-only bundles do. It is safe for concurrent use.
+type Emulator struct {
+	mu sync.Mutex
+
+	// Persistent state.
+	entropySeed       []byte
+	network           aip11.Network
+	pinHash           [32]byte
+	remainingAttempts int
+
+	// Volatile state, cleared by Reset.
+	verified bool
+}
+
+// NewEmulator creates an empty secure element.
+func NewEmulator() *Emulator {
+	return &Emulator{}
+}
+
+// Reset simulates a power cycle, which clears the PIN verification.
+func (e *Emulator) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.verified = false
+}
+
+// Transmit processes a command APDU and returns the response APDU.
+func (e *Emulator) Transmit(command []byte) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, sw := parseCommand(command)
+	if sw != SWSuccess {
+		return (&Response{SW: sw}).marshal(), nil
+	}
+	data, sw := e.process(c)
+	return (&Response{Data: data, SW: sw}).marshal(), nil
+}
+
+func (e *Emulator) process(c *Command) ([]byte, uint16) {
+	switch c.Ins {
+	case InsGenerate, InsRestore:
+		return nil, e.initialize(c)
+	case InsGetStatus:
+		status := []byte{0, byte(e.network), byte(e.remainingAttempts)}
+		if e.entropySeed != nil {
+			status[0] = 1
+		}
+		return status, SWSuccess
+	case InsVerifyPIN, InsChangePIN, InsGetFingerprint, InsDerivePublicRands, InsExportWatchOnly:
+	default:
+		return nil, SWInsNotSupported
+	}
+
+	if e.entropySeed == nil {
+		return nil, SWConditionsNotSatisfied
+	}
+	switch c.Ins {
+	case InsVerifyPIN:
+		return nil, e.verifyPIN(c.Data)
+	case InsChangePIN:
+		return nil, e.changePIN(c.Data)
+	}
+
+	if !e.verified {
+		return nil, SWSecurityNotSatisfied
+	}
+	masterSeed, err := aip11.EntropySeedToNetworkMasterSeed(e.entropySeed, []byte{}, e.network)
+	if err != nil {
+		return nil, SWInternalError
+	}
+	defer clear(masterSeed)
+	switch c.Ins {
+	case InsGetFingerprint:
+		fingerprint, err := aip11.MasterSeedToFingerprint(masterSeed)
+		if err != nil {
+			return nil, SWInternalError
+		}
+		return fingerprint[:], SWSuccess
+	case InsDerivePublicRands:
+		return derivePublicRands(masterSeed, c.Data)
+	default:
+		bundle, err := aip11.NewAccountBundle(masterSeed, aip11.RoleWatchOnly, e.network)
+		if err != nil {
+			return nil, SWInternalError
+		}
+		data, err := bundle.MarshalBinary()
+		if err != nil {
+			return nil, SWInternalError
+		}
+		return data, SWSuccess
+	}
+}
+
+func (e *Emulator) initialize(c *Command) uint16 {
+	if e.entropySeed != nil {
+		return SWCommandNotAllowed
+	}
+	if len(c.Data) < 1 {
+		return SWWrongLength
+	}
+	network, rest := aip11.Network(c.Data[0]), c.Data[1:]
+	if !network.Valid() {
+		return SWWrongData
+	}
+
+	var entropySeed []byte
+	if c.Ins == InsRestore {
+		if len(rest) < 32 {
+			return SWWrongLength
+		}
+		entropySeed, rest = bytes.Clone(rest[:32]), rest[32:]
+	} else {
+		var err error
+		if entropySeed, err = aip11.SampleEntropySeed(); err != nil {
+			return SWInternalError
+		}
+	}
+	if !validPIN(rest) {
+		return SWWrongData
+	}
+
+	e.entropySeed = entropySeed
+	e.network = network
+	e.pinHash = sha3.Sum256(rest)
+	e.remainingAttempts = MaxPINAttempts
+	e.verified = false
+	return SWSuccess
+}
+
+func (e *Emulator) verifyPIN(pin []byte) uint16 {
+	pinHash := sha3.Sum256(pin)
+	if subtle.ConstantTimeCompare(pinHash[:], e.pinHash[:]) == 1 {
+		e.remainingAttempts = MaxPINAttempts
+		e.verified = true
+		return SWSuccess
+	}
+
+	e.verified = false
+	e.remainingAttempts--
+	if e.remainingAttempts <= 0 {
+		e.erase()
+		return SWPINBlocked
+	}
+	return SWPINIncorrect | uint16(e.remainingAttempts)
+}
+
+func (e *Emulator) changePIN(data []byte) uint16 {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return SWWrongLength
+	}
+	oldPIN, newPIN := data[1:1+int(data[0])], data[1+int(data[0]):]
+	if !validPIN(newPIN) {
+		return SWWrongData
+	}
+	if sw := e.verifyPIN(oldPIN); sw != SWSuccess {
+		return sw
+	}
+	e.pinHash = sha3.Sum256(newPIN)
+	return SWSuccess
+}
+
+func derivePublicRands(masterSeed, data []byte) ([]byte, uint16) {
+	if len(data) != 6 {
+		return nil, SWWrongLength
+	}
+	start := binary.BigEndian.Uint32(data)
+	count := binary.BigEndian.Uint16(data[4:])
+	if count == 0 || count > MaxPublicRandsPerCommand || uint64(start)+uint64(count)-1 > uint64(aip11.MaxSeqNo) {
+		return nil, SWWrongData
+	}
+
+	root, err := aip11.MasterSeedToAccountPublicRandRootSeed(masterSeed)
+	if err != nil {
+		return nil, SWInternalError
+	}
+	defer clear(root)
+	publicRands := make([]byte, 0, int(count)*aip11.PublicRandSize)
+	for i := uint32(0); i < uint32(count); i++ {
+		publicRand, err := aip11.DerivePublicRand(root, start+i)
+		if err != nil {
+			return nil, SWInternalError
+		}
+		publicRands = append(publicRands, publicRand...)
+	}
+	return publicRands, SWSuccess
+}
+
+func (e *Emulator) erase() {
+	clear(e.entropySeed)
+	e.entropySeed = nil
+	e.network = 0
+	e.pinHash = [32]byte{}
+	e.remainingAttempts = 0
+	e.verified = false
+}
+
+func validPIN(pin []byte) bool {
+	return len(pin) >= minPINLength && len(pin) <= maxPINLength
+}
@@ -0,0 +1,104 @@
+package secureelement_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/pqabelian/abelian-aip11-go/secureelement"
+	"github.com/stretchr/testify/assert"
+)
+
+func transmit(t *testing.T, emulator *secureelement.Emulator, apdu []byte) *secureelement.Response {
+	responseAPDU, err := emulator.Transmit(apdu)
+	assert.NoError(t, err, "APDU should be transmitted correctly")
+	response, err := secureelement.ParseResponse(responseAPDU)
+	assert.NoError(t, err, "Response should be parsed correctly")
+	return response
+}
+
+func command(t *testing.T, ins byte, data []byte) []byte {
+	apdu, err := (&secureelement.Command{Ins: ins, Data: data}).MarshalBinary()
+	assert.NoError(t, err, "Command should be encoded correctly")
+	return apdu
+}
+
+func TestEmulatorStatusWords(t *testing.T) {
+	emulator := secureelement.NewEmulator()
+	restore := append([]byte{0x00}, make([]byte, 32)...)
+	restore = append(restore, "1234"...)
+	derive := binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint32(nil, 0), 1)
+
+	testCases := []struct {
+		name     string
+		apdu     []byte
+		expected uint16
+	}{
+		{"truncated", []byte{secureelement.ClassAIP11, secureelement.InsGetStatus}, secureelement.SWWrongLength},
+		{"length mismatch", []byte{secureelement.ClassAIP11, secureelement.InsGetStatus, 0, 0, 0, 1}, secureelement.SWWrongLength},
+		{"wrong class", []byte{0x00, secureelement.InsGetStatus, 0, 0, 0, 0}, secureelement.SWClaNotSupported},
+		{"unknown instruction", command(t, 0xff, nil), secureelement.SWInsNotSupported},
+		{"empty fingerprint", command(t, secureelement.InsGetFingerprint, nil), secureelement.SWConditionsNotSatisfied},
+		{"empty verify", command(t, secureelement.InsVerifyPIN, []byte("1234")), secureelement.SWConditionsNotSatisfied},
+		{"restore without network", command(t, secureelement.InsRestore, nil), secureelement.SWWrongLength},
+		{"restore short seed", command(t, secureelement.InsRestore, restore[:20]), secureelement.SWWrongLength},
+		{"restore short PIN", command(t, secureelement.InsRestore, restore[:35]), secureelement.SWWrongData},
+		{"restore", command(t, secureelement.InsRestore, restore), secureelement.SWSuccess},
+		{"restore again", command(t, secureelement.InsRestore, restore), secureelement.SWCommandNotAllowed},
+		{"derive unverified", command(t, secureelement.InsDerivePublicRands, derive), secureelement.SWSecurityNotSatisfied},
+		{"wrong PIN", command(t, secureelement.InsVerifyPIN, []byte("0000")), secureelement.SWPINIncorrect | 2},
+		{"verify", command(t, secureelement.InsVerifyPIN, []byte("1234")), secureelement.SWSuccess},
+		{"derive", command(t, secureelement.InsDerivePublicRands, derive), secureelement.SWSuccess},
+		{"derive wrong length", command(t, secureelement.InsDerivePublicRands, derive[:5]), secureelement.SWWrongLength},
+		{"derive zero count", command(t, secureelement.InsDerivePublicRands, []byte{0, 0, 0, 0, 0, 0}), secureelement.SWWrongData},
+		{"derive too many", command(t, secureelement.InsDerivePublicRands, []byte{0, 0, 0, 0, 0, secureelement.MaxPublicRandsPerCommand + 1}), secureelement.SWWrongData},
+		{"derive overflow", command(t, secureelement.InsDerivePublicRands, []byte{0xff, 0xff, 0xff, 0xff, 0, 2}), secureelement.SWWrongData},
+		{"change PIN malformed", command(t, secureelement.InsChangePIN, []byte{5, '1'}), secureelement.SWWrongLength},
+		{"change PIN short", command(t, secureelement.InsChangePIN, []byte{4, '1', '2', '3', '4', '5'}), secureelement.SWWrongData},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := transmit(t, emulator, tc.apdu)
+			assert.Equal(t, tc.expected, response.SW, "Status word should be returned correctly")
+		})
+	}
+}
+
+func TestEmulatorStatus(t *testing.T) {
+	emulator := secureelement.NewEmulator()
+	response := transmit(t, emulator, command(t, secureelement.InsGetStatus, nil))
+	assert.Equal(t, []byte{0, 0, 0}, response.Data, "Empty status should be returned correctly")
+
+	generate := append([]byte{0x02}, "1234"...)
+	response = transmit(t, emulator, command(t, secureelement.InsGenerate, generate))
+	assert.NoError(t, response.Err(), "Seed should be generated correctly")
+	response = transmit(t, emulator, command(t, secureelement.InsGetStatus, nil))
+	assert.Equal(t, []byte{1, 0x02, secureelement.MaxPINAttempts}, response.Data, "Status should be returned correctly")
+}
+
+func TestResponseErr(t *testing.T) {
+	testCases := []struct {
+		sw       uint16
+		expected error
+	}{
+		{secureelement.SWPINIncorrect | 1, secureelement.ErrPINIncorrect},
+		{secureelement.SWSecurityNotSatisfied, secureelement.ErrPINRequired},
+		{secureelement.SWPINBlocked, secureelement.ErrPINBlocked},
+		{secureelement.SWConditionsNotSatisfied, secureelement.ErrNotInitialized},
+		{secureelement.SWCommandNotAllowed, secureelement.ErrAlreadyInitialized},
+		{secureelement.SWWrongLength, secureelement.ErrWrongLength},
+		{secureelement.SWWrongData, secureelement.ErrWrongData},
+		{secureelement.SWInsNotSupported, secureelement.ErrNotSupported},
+		{secureelement.SWClaNotSupported, secureelement.ErrNotSupported},
+		{secureelement.SWInternalError, secureelement.ErrInternal},
+	}
+	for _, tc := range testCases {
+		err := (&secureelement.Response{SW: tc.sw}).Err()
+		assert.ErrorIs(t, err, tc.expected, "Status word should be mapped correctly")
+	}
+	assert.NoError(t, (&secureelement.Response{SW: secureelement.SWSuccess}).Err(), "Success should be mapped correctly")
+	assert.Error(t, (&secureelement.Response{SW: 0x6f01}).Err(), "Unknown status word should be mapped correctly")
+	assert.NotErrorIs(t, (&secureelement.Response{SW: secureelement.SWInternalError}).Err(), secureelement.ErrNotInitialized, "Internal error should not be mapped to an empty element")
+
+	_, err := secureelement.ParseResponse([]byte{0x90})
+	assert.ErrorIs(t, err, secureelement.ErrAPDUMalformed, "Truncated response should be rejected correctly")
+}
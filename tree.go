@@ -0,0 +1,95 @@
+package aip11
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// This file provides hierarchical sub-master seeds.
+//
+// A sub-master seed is derived from its parent master seed with
+//
+//	subMasterSeed = PRF(parentMasterSeed, "SubMasterSeed" || encode_string(component))
+//
+// and is itself a 64-byte master seed, so MasterSeedToAccountRootSeeds and
+// MasterSeedToAccountPublicRandRootSeed apply to it unchanged. A path such as
+// "org/finance/desk-1" applies one step per component. By the security of
+// PRF, the holder of a sub-master seed learns nothing about its parent or its
+// siblings.
+
+// Errors
+
+var (
+	ErrSubMasterPathInvalid = errors.New("sub-master path is invalid")
+)
+
+const (
+	subMasterSeedLabel = "SubMasterSeed"
+
+	// MaxSubMasterPathComponentLength is the maximum length of a component in bytes.
+	MaxSubMasterPathComponentLength = 64
+)
+
+// SubMasterPath is a path of sub-master seed components, from the root down.
+type SubMasterPath []string
+
+// ParseSubMasterPath parses a path of components separated by '/'. Components
+// consist of ASCII letters, digits, '.', '_' and '-'. The empty string is the
+// empty path, which denotes the master seed itself.
+func ParseSubMasterPath(s string) (SubMasterPath, error) {
+	if s == "" {
+		return SubMasterPath{}, nil
+	}
+	path := SubMasterPath(strings.Split(s, "/"))
+	if err := path.Validate(); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// Validate checks that every component of the path is well-formed.
+func (p SubMasterPath) Validate() error {
+	for _, component := range p {
+		if !validSubMasterPathComponent(component) {
+			return ErrSubMasterPathInvalid
+		}
+	}
+	return nil
+}
+
+// String returns the path with components separated by '/'.
+func (p SubMasterPath) String() string {
+	return strings.Join(p, "/")
+}
+
+// Child returns the path extended by the given components.
+func (p SubMasterPath) Child(components ...string) SubMasterPath {
+	child := make(SubMasterPath, 0, len(p)+len(components))
+	child = append(child, p...)
+	return append(child, components...)
+}
+
+// DeriveSubMasterSeed derives the sub-master seed at path below the master seed.
+func DeriveSubMasterSeed(masterSeed []byte, path SubMasterPath) ([]byte, error) {
+	seed, err := softwareKeyBytes(defaultDeriver.DeriveSubMasterSeed(SoftwareKey(masterSeed), path))
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(seed), nil
+}
+
+func validSubMasterPathComponent(component string) bool {
+	if len(component) == 0 || len(component) > MaxSubMasterPathComponentLength {
+		return false
+	}
+	for _, c := range component {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.' || c == '_' || c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
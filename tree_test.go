@@ -0,0 +1,94 @@
+package aip11_test
+
+import (
+	"fmt"
+	"testing"
+
+	aip11 "github.com/pqabelian/abelian-aip11-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleDeriveSubMasterSeed() {
+	entropySeed, _ := aip11.SampleEntropySeed()
+	masterSeed, _ := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	path, _ := aip11.ParseSubMasterPath("org/finance/desk-1")
+	deskSeed, _ := aip11.DeriveSubMasterSeed(masterSeed, path)
+	accountRootSeeds, _ := aip11.MasterSeedToAccountRootSeeds(deskSeed)
+	fmt.Println(len(deskSeed), len(accountRootSeeds))
+	// Output: 64 5
+}
+
+func TestParseSubMasterPath(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected aip11.SubMasterPath
+	}{
+		{input: "", expected: aip11.SubMasterPath{}},
+		{input: "org", expected: aip11.SubMasterPath{"org"}},
+		{input: "org/finance/desk-1", expected: aip11.SubMasterPath{"org", "finance", "desk-1"}},
+		{input: "A.b_C-0", expected: aip11.SubMasterPath{"A.b_C-0"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			path, err := aip11.ParseSubMasterPath(tc.input)
+			assert.NoError(t, err, "Path should be parsed correctly")
+			assert.Equal(t, tc.expected, path, "Parsed path should be the same")
+			assert.Equal(t, tc.input, path.String(), "Path should be formatted correctly")
+		})
+	}
+
+	for _, input := range []string{"/org", "org/", "org//desk", "org/desk 1", "org/désk"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := aip11.ParseSubMasterPath(input)
+			assert.ErrorIs(t, err, aip11.ErrSubMasterPathInvalid, "Invalid path should be rejected")
+		})
+	}
+}
+
+func TestDeriveSubMasterSeed(t *testing.T) {
+	entropySeed, err := aip11.SampleEntropySeed()
+	assert.NoError(t, err, "Entropy seed should be sampled correctly")
+	orgSeed, err := aip11.EntropySeedToMasterSeed(entropySeed, []byte{})
+	assert.NoError(t, err, "Master seed should be generated correctly")
+
+	self, err := aip11.DeriveSubMasterSeed(orgSeed, aip11.SubMasterPath{})
+	assert.NoError(t, err, "Empty path should be derived correctly")
+	assert.Equal(t, orgSeed, self, "Empty path should denote the master seed")
+
+	finance := aip11.SubMasterPath{"finance"}
+	financeSeed, err := aip11.DeriveSubMasterSeed(orgSeed, finance)
+	assert.NoError(t, err, "Department seed should be derived correctly")
+
+	// The department derives its desks from the handed sub-master seed alone,
+	// and obtains the same seeds the organisation derives from the full path.
+	deskSeed, err := aip11.DeriveSubMasterSeed(financeSeed, aip11.SubMasterPath{"desk-1"})
+	assert.NoError(t, err, "Desk seed should be derived correctly")
+	deskSeedFromOrg, err := aip11.DeriveSubMasterSeed(orgSeed, finance.Child("desk-1"))
+	assert.NoError(t, err, "Desk seed should be derived correctly")
+	assert.Equal(t, deskSeedFromOrg, deskSeed, "Delegated derivation should match the full path")
+
+	// Siblings, parents and children are pairwise independent.
+	tradingSeed, err := aip11.DeriveSubMasterSeed(orgSeed, aip11.SubMasterPath{"trading"})
+	assert.NoError(t, err, "Department seed should be derived correctly")
+	desk2Seed, err := aip11.DeriveSubMasterSeed(financeSeed, aip11.SubMasterPath{"desk-2"})
+	assert.NoError(t, err, "Desk seed should be derived correctly")
+	seeds := [][]byte{orgSeed, financeSeed, tradingSeed, deskSeed, desk2Seed}
+	for i := range seeds {
+		for j := i + 1; j < len(seeds); j++ {
+			assert.NotEqual(t, seeds[i], seeds[j], "Seeds %d and %d should be different", i, j)
+		}
+	}
+
+	orgRootSeeds, err := aip11.MasterSeedToAccountRootSeeds(orgSeed)
+	assert.NoError(t, err, "Account root seeds should be generated correctly")
+	deskRootSeeds, err := aip11.MasterSeedToAccountRootSeeds(deskSeed)
+	assert.NoError(t, err, "Account root seeds should be generated correctly")
+	for i := range orgRootSeeds {
+		assert.NotEqual(t, orgRootSeeds[i], deskRootSeeds[i], "Desk account should be independent of the organisation account")
+	}
+
+	_, err = aip11.DeriveSubMasterSeed(orgSeed[:32], finance)
+	assert.ErrorIs(t, err, aip11.ErrMasterSeedInvalid, "Invalid master seed should be rejected")
+	_, err = aip11.DeriveSubMasterSeed(orgSeed, aip11.SubMasterPath{""})
+	assert.ErrorIs(t, err, aip11.ErrSubMasterPathInvalid, "Invalid path should be rejected")
+}